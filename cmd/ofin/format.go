@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/format"
+)
+
+func fmtCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "list the scripts that are not formatted and exit with 1 if there are any")
+	write := flags.Bool("write", false, "rewrite the scripts in place instead of printing them")
+	indent := flags.Int("indent", format.DefaultIndent, "indent blocks by `width` spaces")
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if *check && *write {
+		fmt.Fprintln(stderr, "ofin fmt: --check and --write cannot be used together")
+		return exitUsage
+	}
+	if *indent <= 0 {
+		fmt.Fprintf(stderr, "ofin fmt: indent width must be positive, got %d\n", *indent)
+		return exitUsage
+	}
+	files, code := scriptFiles("fmt", flags.Args(), stderr)
+	if code != exitOK {
+		return code
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "ofin fmt: %v\n", err)
+			code = exitUsage
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "ofin fmt: %v\n", err)
+			code = exitUsage
+			continue
+		}
+		source := string(data)
+		formatted, err := format.Source(source, file, *indent)
+		if err != nil {
+			fmt.Fprintln(stderr, merror.Diagnostic(err, formatted))
+			code = exitUsage
+			continue
+		}
+
+		switch {
+		case *check:
+			if formatted != source {
+				fmt.Fprintln(stdout, file)
+				if code == exitOK {
+					code = exitFailed
+				}
+			}
+		case *write:
+			if formatted == source {
+				continue
+			}
+			if err := os.WriteFile(file, []byte(formatted), info.Mode().Perm()); err != nil {
+				fmt.Fprintf(stderr, "ofin fmt: %v\n", err)
+				if code == exitOK {
+					code = exitFailed
+				}
+			}
+		default:
+			fmt.Fprint(stdout, formatted)
+		}
+	}
+	return code
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/itsert/ofin/script/tools"
+)
+
+// generate writes the ast package from the node definitions below. It is a
+// development command and is not listed in the help.
+func generate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "ofin generate: expected one output directory")
+		return exitUsage
+	}
+	dir := flags.Arg(0)
+	tools.GenerateAST(dir, "Expression", []string{
+		"Assign   : Name token.Token, Expr Expression",
+		"Binary : Left Expression, Operator token.Token, Right Expression",
+		"Call : Callee Expression, Paren token.Token, Arguments []Expression",
+		"Grouping : Expr Expression",
+		"Literal : Value interface{}",
+		"Logical : Left Expression, Operator token.Token, Right Expression",
+		"Unary : Operator token.Token, Right Expression",
+		"Variable : Name token.Token",
+		"List : Bracket token.Token, Elements []Expression",
+		"Map : Brace token.Token, Keys []Expression, Values []Expression",
+		"Index : Object Expression, Bracket token.Token, Key Expression",
+		"SetIndex : Object Expression, Bracket token.Token, Key Expression, Value Expression",
+		"Get : Object Expression, Name token.Token",
+		"Set : Object Expression, Name token.Token, Value Expression",
+	})
+	tools.GenerateAST(dir, "Statement", []string{
+		"StmtExpression : Expr Expression",
+		"If : Condition Expression, ThenBranch Statement, ElseBranch Statement",
+		"Print : Expr Expression",
+		"When : Keyword token.Token, Expr Expression, Body Statement",
+		"Then : Keyword token.Token, Expr Expression, Body Statement",
+		"And : Keyword token.Token, Expr Expression",
+		"Scenario : Keyword token.Token, Label string",
+		"Var : Keyword token.Token, Name token.Token, Initializer Expression",
+		"While : Condition Expression, Body Statement",
+		"Block : Statements []Statement, BlockState environment.State",
+		"DoNoting : Name token.Token",
+		"Function : Name token.Token, Params []token.Token, Defaults []Expression, Body []Statement",
+		"Return : Keyword token.Token, Value Expression",
+		"For : Name token.Token, Iterable Expression, Body Statement",
+		"Import : Keyword token.Token, Path token.Token, Module *Module",
+	})
+	return exitOK
+}
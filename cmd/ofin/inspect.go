@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/debug"
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/lsp"
+	"github.com/itsert/ofin/script/parser"
+	"github.com/itsert/ofin/script/repl"
+)
+
+func tokensCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	source, code := readScript("tokens", flags.Args(), stderr)
+	if code != exitOK {
+		return code
+	}
+
+	tokens, err := lexer.NewLexer(source, flags.Arg(0)).Tokenize()
+	for _, tok := range tokens {
+		fmt.Fprintf(stdout, "%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Lexeme)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, merror.Diagnostic(err, source))
+		return exitUsage
+	}
+	return exitOK
+}
+
+func astCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	format := flags.String("format", "sexpr", "print the tree in `format`: sexpr, or json with positions")
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if *format != "json" && *format != "sexpr" {
+		fmt.Fprintf(stderr, "ofin ast: unknown format %q\n", *format)
+		return exitUsage
+	}
+	source, code := readScript("ast", flags.Args(), stderr)
+	if code != exitOK {
+		return code
+	}
+
+	statements, err := parser.NewParser(lexer.NewLexer(source, flags.Arg(0))).ParseProgram()
+	if err != nil {
+		fmt.Fprintln(stderr, merror.Diagnostic(err, source))
+		return exitUsage
+	}
+	if *format == "sexpr" {
+		fmt.Fprint(stdout, interpreter.NewPrettyPrinter().PrintStatements(statements))
+		return exitOK
+	}
+	out, err := interpreter.NewJSONPrinter().Marshal(statements)
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin ast: %v\n", err)
+		return exitFailed
+	}
+	fmt.Fprintln(stdout, string(out))
+	return exitOK
+}
+
+// readScript reads the single script a command takes.
+func readScript(name string, args []string, stderr io.Writer) (string, int) {
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "ofin %s: expected one script, got %d\n", name, len(args))
+		return "", exitUsage
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin %s: %v\n", name, err)
+		return "", exitUsage
+	}
+	return string(data), exitOK
+}
+
+func replCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	repl.Start(os.Stdin, stdout)
+	return exitOK
+}
+
+func lspCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if err := lsp.NewServer(os.Stdin, stdout).Run(); err != nil {
+		fmt.Fprintf(stderr, "ofin lsp: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+func debugCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	listen := flags.String("listen", "127.0.0.1:4711", "accept the debug client on `address`")
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintf(stderr, "ofin debug: expected at most one script, got %d\n", flags.NArg())
+		return exitUsage
+	}
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin debug: %v\n", err)
+		return exitUsage
+	}
+	defer listener.Close()
+	fmt.Fprintf(stderr, "ofin debug: listening on %s\n", listener.Addr())
+	conn, err := listener.Accept()
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin debug: %v\n", err)
+		return exitFailed
+	}
+	defer conn.Close()
+
+	session := debug.NewSession(conn, conn, flags.Arg(0))
+	err = session.Serve()
+	session.Wait()
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin debug: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes shared by every command.
+const (
+	exitOK = 0
+	// exitFailed means a scenario failed or errored, or the script raised a
+	// runtime error.
+	exitFailed = 1
+	// exitUsage means the command line or a script could not be used: bad
+	// flags, missing files or syntax errors.
+	exitUsage = 2
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string, stdout io.Writer, stderr io.Writer) int
+	hidden  bool
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "run", args: "[flags] <files|dirs...>", summary: "run the scenarios of scripts", run: runCommand},
+		{name: "check", args: "<files|dirs...>", summary: "report syntax errors without running anything", run: checkCommand},
+		{name: "fmt", args: "[flags] <files|dirs...>", summary: "format scripts canonically", run: fmtCommand},
+		{name: "tokens", args: "<file>", summary: "print the tokens of a script", run: tokensCommand},
+		{name: "ast", args: "[flags] <file>", summary: "print the syntax tree of a script", run: astCommand},
+		{name: "repl", args: "", summary: "start an interactive session", run: replCommand},
+		{name: "debug", args: "[flags] [file]", summary: "debug a script with a client speaking the debug adapter protocol", run: debugCommand},
+		{name: "lsp", args: "", summary: "serve editors over the language server protocol on stdin and stdout", run: lspCommand},
+		{name: "help", args: "[command]", summary: "show help for ofin or a command", run: helpCommand},
+		{name: "generate", args: "<output directory>", summary: "write the ast package from its node definitions", run: generate, hidden: true},
+	}
+}
+
+func main() {
+	os.Exit(ofin(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// ofin runs the command named by args[0] and returns the exit code.
+func ofin(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "ofin: unknown command %q\n", args[0])
+		fmt.Fprintln(stderr, "Run 'ofin help' for usage.")
+		return exitUsage
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "ofin runs Gherkin-style acceptance scripts.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "\tofin <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		if !cmd.hidden {
+			fmt.Fprintf(w, "\t%-8s %s\n", cmd.name, cmd.summary)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Directories are expanded to the *.ac files they contain.")
+	fmt.Fprintln(w, "Exit codes: 0 on success, 1 when a scenario fails or errors, 2 on usage or syntax errors.")
+}
+
+func helpCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stdout)
+		return exitOK
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "ofin help: unknown command %q\n", args[0])
+		return exitUsage
+	}
+	return cmd.run([]string{"--help"}, stdout, stderr)
+}
+
+// commandUsage prints the usage line of cmd followed by its flags.
+func commandUsage(w io.Writer, name string, flagUsage func()) {
+	cmd := lookupCommand(name)
+	fmt.Fprintf(w, "usage: ofin %s %s\n", cmd.name, cmd.args)
+	if cmd.summary != "" {
+		fmt.Fprintf(w, "\n%s%s.\n", strings.ToUpper(cmd.summary[:1]), cmd.summary[1:])
+	}
+	if flagUsage != nil {
+		fmt.Fprintln(w)
+		flagUsage()
+	}
+}
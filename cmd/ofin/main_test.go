@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScripts(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCommands(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"pass/a.ac":  "Scenario \"a\":\n    Given a = 1\n    Then a == 1\n",
+		"pass/b.ac":  "Scenario \"b\":\n    Given b = 2\n    Then b == 2\n",
+		"pass/c.txt": "not a script",
+		"fail.ac":    "Scenario \"fails\":\n    Given a = 1\n    Then a == 2\n",
+		"syntax.ac":  "Given a = (1\n",
+		"spin.ac":    "fn spin():\n    Given i = 0\n    while true:\n        i = i + 1\nScenario \"spins\":\n    When spin()\n",
+		"output.ac":  "Scenario \"quiet\":\n    Given a = 1\n    print \"hidden\"\nScenario \"loud\":\n    Given a = 1\n    print_all(\"a is\", a)\n    Then a == 2\n",
+	})
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{}, exitUsage, "", "Usage:"},
+		{[]string{"run", path("output.ac")}, exitFailed, "failed: [line 7] assertion failed: a == 2 (left: 1, right: 2)\n    a is 1\n", ""},
+		{[]string{"run", "-v", path("output.ac")}, exitFailed, "hidden\na is 1\n", ""},
+		{[]string{"run", "--scenario-timeout", "10ms", path("spin.ac")}, exitFailed, "spin.ac:3:5 scenario timed out after 10ms", ""},
+		{[]string{"run", "--timeout", "10ms", path("spin.ac")}, exitFailed, "spin.ac:3:5 run timed out", "spin.ac:3:5 run timed out"},
+		{[]string{"--help"}, exitOK, "Commands:", ""},
+		{[]string{"help", "run"}, exitOK, "usage: ofin run", ""},
+		{[]string{"help", "generate"}, exitOK, "usage: ofin generate <output directory>", ""},
+		{[]string{"generate"}, exitUsage, "", "expected one output directory"},
+		{[]string{"bogus"}, exitUsage, "", `unknown command "bogus"`},
+		{[]string{"run"}, exitUsage, "", "no scripts given"},
+		{[]string{"run", path("pass")}, exitOK, "2 scenarios (2 passed)", ""},
+		{[]string{"run", path("pass/*.ac"), path("fail.ac")}, exitFailed, "3 scenarios (2 passed, 1 failed)", ""},
+		{[]string{"run", "--format", "tap", path("fail.ac")}, exitFailed, "not ok 1 - fails", ""},
+		{[]string{"run", "--format", "tap", "-v", path("output.ac")}, exitFailed, "TAP version 13\n1..2\n", "hidden\na is 1\n"},
+		{[]string{"run", path("syntax.ac")}, exitUsage, "0 scenarios", "syntax.ac:1:13 Expect ')' after expression"},
+		{[]string{"run", path("missing.ac")}, exitUsage, "", "no such file"},
+		{[]string{"check", path("pass"), path("fail.ac")}, exitOK, "", ""},
+		{[]string{"check", path("syntax.ac")}, exitUsage, "", "Expect ')' after expression"},
+		{[]string{"tokens", path("syntax.ac")}, exitOK, "1:1\tGIVEN\t\"Given\"", ""},
+		{[]string{"ast", path("fail.ac")}, exitOK, "(Scenario \"fails\")\n(block (Given a 1) (Then (== a 2)))\n", ""},
+		{[]string{"ast", "--format", "json", path("fail.ac")}, exitOK, `"Label": "fails"`, ""},
+		{[]string{"ast", "--format", "json", path("fail.ac")}, exitOK, `"Node": "Scenario"`, ""},
+		{[]string{"ast", "--format", "yaml", path("fail.ac")}, exitUsage, "", `unknown format "yaml"`},
+	}
+	for i, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := ofin(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Fatalf("tests[%d] %v - exit code wrong. expected=%d, got=%d (stderr %q)", i, tt.args, tt.code, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), tt.stdout) {
+			t.Fatalf("tests[%d] %v - stdout wrong. expected to contain %q, got=%q", i, tt.args, tt.stdout, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), tt.stderr) {
+			t.Fatalf("tests[%d] %v - stderr wrong. expected to contain %q, got=%q", i, tt.args, tt.stderr, stderr.String())
+		}
+	}
+}
+
+func TestFmtCommand(t *testing.T) {
+	messy := "Scenario \"a\":\n  given a=1 // one\n  then a==1\n"
+	tidy := "Scenario \"a\":\n    Given a = 1 // one\n    Then a == 1\n"
+	dir := writeScripts(t, map[string]string{
+		"messy.ac":  messy,
+		"tidy.ac":   tidy,
+		"syntax.ac": "Given a = (1\n",
+	})
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"fmt", path("messy.ac")}, exitOK, tidy, ""},
+		{[]string{"fmt", "--indent", "2", path("tidy.ac")}, exitOK, "\n  Given a = 1", ""},
+		{[]string{"fmt", "--check", path("tidy.ac")}, exitOK, "", ""},
+		{[]string{"fmt", "--check", dir}, exitUsage, path("messy.ac"), "Expect ')' after expression"},
+		{[]string{"fmt", "--check", "--write", dir}, exitUsage, "", "cannot be used together"},
+		{[]string{"fmt", "--write", path("messy.ac")}, exitOK, "", ""},
+		{[]string{"fmt", "--check", path("messy.ac"), path("tidy.ac")}, exitOK, "", ""},
+	}
+	for i, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := ofin(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Fatalf("tests[%d] %v - exit code wrong. expected=%d, got=%d (stderr %q)", i, tt.args, tt.code, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), tt.stdout) {
+			t.Fatalf("tests[%d] %v - stdout wrong. expected to contain %q, got=%q", i, tt.args, tt.stdout, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), tt.stderr) {
+			t.Fatalf("tests[%d] %v - stderr wrong. expected to contain %q, got=%q", i, tt.args, tt.stderr, stderr.String())
+		}
+	}
+}
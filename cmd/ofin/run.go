@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/report"
+	"github.com/itsert/ofin/script/resolver"
+	"github.com/itsert/ofin/script/result"
+)
+
+func runCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	format := flags.String("format", "summary", "print results in `format`: summary or tap")
+	continueOnFailure := flags.Bool("continue-on-failure", false, "keep running a scenario after one of its assertions fails")
+	verbose := flags.Bool("v", false, "print what every scenario prints as it runs, not only what failing ones printed")
+	timeout := flags.Duration("timeout", 0, "stop the run after `duration`, erroring the scenario running (0 for no limit)")
+	scenarioTimeout := flags.Duration("scenario-timeout", 0, "error any scenario still running after `duration` (0 for no limit)")
+	var reports reportFlags
+	flags.Var(&reports, "report", "write a report as `format=path`, e.g. junit=out.xml or cucumber=out.json (repeatable)")
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	if *format != "summary" && *format != "tap" {
+		fmt.Fprintf(stderr, "ofin run: unknown format %q\n", *format)
+		return exitUsage
+	}
+	files, code := scriptFiles("run", flags.Args(), stderr)
+	if code != exitOK {
+		return code
+	}
+	policy := interpreter.StopScenario
+	if *continueOnFailure {
+		policy = interpreter.ContinueScenario
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	config := runConfig{policy: policy, capture: !*verbose, scenarioTimeout: *scenarioTimeout, output: stdout}
+	if *format == "tap" {
+		// Keep stdout a TAP stream that parsers can read.
+		config.output = stderr
+	}
+	modules := loader.NewLoader()
+	var runs []*result.Run
+	for _, file := range files {
+		run, fileCode := runFile(ctx, modules, file, config, stderr)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		if fileCode > code {
+			code = fileCode
+		}
+	}
+
+	if *format == "tap" {
+		report.TAP(stdout, runs)
+	} else {
+		report.Summary(stdout, mergeRuns(runs))
+	}
+	for _, r := range reports {
+		if err := r.write(runs); err != nil {
+			fmt.Fprintf(stderr, "ofin run: writing %s report: %v\n", r.format, err)
+			return exitFailed
+		}
+	}
+	return code
+}
+
+// runConfig is how runFile sets up the interpreter of each script.
+type runConfig struct {
+	policy interpreter.AssertionPolicy
+	// capture keeps what scenarios print in the results rather than
+	// writing it to output.
+	capture         bool
+	scenarioTimeout time.Duration
+	// output receives what scripts print.
+	output io.Writer
+}
+
+// runFile interprets a single script. The run is nil when the script could
+// not be loaded or resolved.
+func runFile(ctx context.Context, modules *loader.Loader, file string, config runConfig, stderr io.Writer) (*result.Run, int) {
+	module, err := modules.Load(file)
+	if err != nil {
+		fmt.Fprintln(stderr, modules.Diagnostic(err))
+		return nil, exitUsage
+	}
+	i := interpreter.NewInterpreter()
+	i.File = module.File
+	i.Policy = config.policy
+	i.Capture = config.capture
+	i.ScenarioTimeout = config.scenarioTimeout
+	i.Output = config.output
+	i.Diagnostics = stderr
+	if err := resolver.NewResolver(i, module.File).Resolve(module.Statements); err != nil {
+		fmt.Fprintln(stderr, modules.Diagnostic(err))
+		return nil, exitUsage
+	}
+
+	code := exitOK
+	if err := i.Interpret(ctx, module.Statements); err != nil {
+		fmt.Fprintln(stderr, modules.Diagnostic(err))
+		code = exitFailed
+	}
+	if i.Failed() {
+		code = exitFailed
+	}
+	run := i.Results()
+	run.File = file
+	return run, code
+}
+
+// mergeRuns combines runs into one so a single summary covers every script.
+func mergeRuns(runs []*result.Run) *result.Run {
+	merged := result.NewRun()
+	for _, run := range runs {
+		merged.Scenarios = append(merged.Scenarios, run.Scenarios...)
+		merged.Duration += run.Duration
+	}
+	return merged
+}
+
+func checkCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	if code, ok := parseFlags(flags, args, stdout, stderr); !ok {
+		return code
+	}
+	files, code := scriptFiles("check", flags.Args(), stderr)
+	if code != exitOK {
+		return code
+	}
+
+	modules := loader.NewLoader()
+	for _, file := range files {
+		module, err := modules.Load(file)
+		if err == nil {
+			err = resolver.NewResolver(interpreter.NewInterpreter(), module.File).Resolve(module.Statements)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, modules.Diagnostic(err))
+			code = exitUsage
+		}
+	}
+	return code
+}
+
+// parseFlags parses args into flags. When ok is false the command is done
+// and should return code: help was asked for or the flags were wrong.
+func parseFlags(flags *flag.FlagSet, args []string, stdout io.Writer, stderr io.Writer) (code int, ok bool) {
+	flags.SetOutput(io.Discard)
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		flags.SetOutput(stdout)
+		commandUsage(stdout, flags.Name(), flags.PrintDefaults)
+		return exitOK, false
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ofin %s: %v\n", flags.Name(), err)
+		flags.SetOutput(stderr)
+		commandUsage(stderr, flags.Name(), flags.PrintDefaults)
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// scriptFiles expands the arguments of a command into script paths. A
+// directory stands for the *.ac files directly inside it and a pattern for
+// the files it matches.
+func scriptFiles(name string, args []string, stderr io.Writer) ([]string, int) {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, "ofin %s: no scripts given\n", name)
+		return nil, exitUsage
+	}
+	var files []string
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil || len(matches) == 0 {
+				fmt.Fprintf(stderr, "ofin %s: no scripts match %s\n", name, arg)
+				return nil, exitUsage
+			}
+			files = append(files, matches...)
+			continue
+		}
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(stderr, "ofin %s: %v\n", name, err)
+			return nil, exitUsage
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(arg, "*.ac"))
+		if len(matches) == 0 {
+			fmt.Fprintf(stderr, "ofin %s: no .ac files in %s\n", name, arg)
+			return nil, exitUsage
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, exitOK
+}
+
+// reportFlag is a single `--report format=path` argument.
+type reportFlag struct {
+	format string
+	path   string
+}
+
+func (r reportFlag) write(runs []*result.Run) error {
+	f, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.Reporters[r.format](f, runs)
+}
+
+type reportFlags []reportFlag
+
+func (r *reportFlags) String() string {
+	var parts []string
+	for _, flag := range *r {
+		parts = append(parts, flag.format+"="+flag.path)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (r *reportFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("expected format=path, got %q", value)
+	}
+	if _, ok := report.Reporters[parts[0]]; !ok {
+		return fmt.Errorf("unknown report format %q", parts[0])
+	}
+	*r = append(*r, reportFlag{format: parts[0], path: parts[1]})
+	return nil
+}
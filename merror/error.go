@@ -2,17 +2,184 @@ package merror
 
 import (
 	"fmt"
-	"os"
+	"sort"
+	"strings"
 
 	"github.com/itsert/ofin/script/token"
 )
 
-func Error(fileName string, line int, start int, message string) {
-	fmt.Fprintf(os.Stderr, "%s:%d:%d %s\n", fileName, line, start, message)
-	panic(message)
+// SyntaxError is a problem found in a script before it runs, by the lexer,
+// the parser or the resolver.
+type SyntaxError struct {
+	File string
+	Line int
+	// Column is 1-based; it is 0 when only the line is known.
+	Column int
+	// Token is the token the error was found at. It is the zero Token for
+	// errors raised by the lexer.
+	Token   token.Token
+	Message string
 }
 
-func RuntimeError(token token.Token, message string) {
-	fmt.Fprintf(os.Stderr, fmt.Sprintf("\n[line %d]", token.Line))
-	panic(message)
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s:%d:%d %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ErrorList is every SyntaxError found in a script.
+type ErrorList []*SyntaxError
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Sort orders the errors by their position in the source.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].Line != l[j].Line {
+			return l[i].Line < l[j].Line
+		}
+		return l[i].Column < l[j].Column
+	})
+}
+
+// Err returns l as an error, or nil when it is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// RuntimeError is raised by the interpreter while executing a script.
+type RuntimeError struct {
+	File string
+	Line int
+	// Column is 1-based; it is 0 when only the line is known.
+	Column  int
+	Token   token.Token
+	Message string
+}
+
+// Error formats e as `file:line:column message`, like a SyntaxError,
+// leaving out the column when it is not known. Without a file, as for code
+// typed at the REPL, it is `[line N] message`.
+func (e *RuntimeError) Error() string {
+	switch {
+	case e.File == "":
+		return fmt.Sprintf("[line %d] %s", e.Line, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// Error panics with a SyntaxError at a position of the source. The lexer
+// uses it before there is a token to point at.
+func Error(fileName string, line int, column int, message string) {
+	panic(&SyntaxError{
+		File:    fileName,
+		Line:    line,
+		Column:  column,
+		Message: message,
+	})
+}
+
+// TokenError panics with a SyntaxError at tok. fileName is only used when
+// the token does not record the file it was read from.
+func TokenError(fileName string, tok token.Token, message string) {
+	if tok.File != "" {
+		fileName = tok.File
+	}
+	panic(&SyntaxError{
+		File:    fileName,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Token:   tok,
+		Message: message,
+	})
+}
+
+// Runtime panics with a RuntimeError at tok.
+func Runtime(tok token.Token, message string) {
+	panic(&RuntimeError{
+		File:    tok.File,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Token:   tok,
+		Message: message,
+	})
+}
+
+// RuntimeAt panics with a RuntimeError at the start of span, for errors
+// that belong to a whole statement rather than one of its tokens.
+func RuntimeAt(span token.Span, message string) {
+	panic(&RuntimeError{
+		File:    span.File,
+		Line:    span.Start.Line,
+		Column:  span.Start.Column,
+		Message: message,
+	})
+}
+
+// Diagnostic renders err, a SyntaxError, an ErrorList or a RuntimeError, as
+// `file:line:column message` followed by the offending line of source with
+// the token underlined:
+//
+//	test.ac:9:15 Expected expression
+//	    Then a == )
+//	              ^
+//
+// Any other error is rendered with its Error method.
+func Diagnostic(err error, source string) string {
+	switch e := err.(type) {
+	case ErrorList:
+		diagnostics := make([]string, len(e))
+		for i, syntaxError := range e {
+			diagnostics[i] = Diagnostic(syntaxError, source)
+		}
+		return strings.Join(diagnostics, "\n")
+	case *SyntaxError:
+		return diagnostic(e.File, e.Line, e.Column, e.Token, e.Message, source)
+	case *RuntimeError:
+		return diagnostic(e.File, e.Line, e.Column, e.Token, e.Message, source)
+	}
+	return err.Error()
+}
+
+func diagnostic(file string, line int, column int, tok token.Token, message string, source string) string {
+	header := fmt.Sprintf("%s:%d:%d %s", file, line, column, message)
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) || column < 1 {
+		return header
+	}
+	text := strings.TrimRight(lines[line-1], "\r")
+	if column > len(text)+1 {
+		return header
+	}
+
+	width := 1
+	if tok.Line == line && tok.EndColumn > tok.Column {
+		width = tok.EndColumn - tok.Column
+	}
+	if column-1+width > len(text) {
+		width = len(text) - (column - 1)
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	// Keep the tabs of the source line so the caret lines up under it.
+	var indent strings.Builder
+	for _, ch := range text[:column-1] {
+		if ch == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteRune(' ')
+		}
+	}
+	return fmt.Sprintf("%s\n    %s\n    %s%s", header, text, indent.String(), strings.Repeat("^", width))
 }
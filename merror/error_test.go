@@ -0,0 +1,50 @@
+package merror
+
+import (
+	"testing"
+
+	"github.com/itsert/ofin/script/token"
+)
+
+func TestDiagnostic(t *testing.T) {
+	source := "Scenario \"s\":\n\tGiven a = 1\n\tThen a == nope\n"
+	nope := token.Token{Type: token.IDENTIFIER, Lexeme: "nope", Line: 3, Column: 12, EndColumn: 16, File: "test.ac"}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{
+			&RuntimeError{File: "test.ac", Line: 3, Column: 12, Token: nope, Message: "variable nope is undefined"},
+			"test.ac:3:12 variable nope is undefined\n    \tThen a == nope\n    \t          ^^^^",
+		},
+		{
+			ErrorList{
+				&SyntaxError{File: "test.ac", Line: 2, Column: 11, Message: "unexpected character."},
+				&SyntaxError{File: "test.ac", Line: 9, Column: 1, Message: "past the end"},
+			},
+			"test.ac:2:11 unexpected character.\n    \tGiven a = 1\n    \t         ^\ntest.ac:9:1 past the end",
+		},
+	}
+	for i, tt := range tests {
+		if got := Diagnostic(tt.err, source); got != tt.expected {
+			t.Fatalf("tests[%d] - diagnostic wrong. expected=%q, got=%q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestRuntimeError(t *testing.T) {
+	tests := []struct {
+		err      *RuntimeError
+		expected string
+	}{
+		{&RuntimeError{File: "test.ac", Line: 3, Column: 12, Message: "variable nope is undefined"}, "test.ac:3:12 variable nope is undefined"},
+		{&RuntimeError{File: "test.ac", Line: 3, Message: "run timed out"}, "test.ac:3 run timed out"},
+		{&RuntimeError{Line: 3, Column: 12, Message: "variable nope is undefined"}, "[line 3] variable nope is undefined"},
+	}
+	for i, tt := range tests {
+		if got := tt.err.Error(); got != tt.expected {
+			t.Fatalf("tests[%d] - error wrong. expected=%q, got=%q", i, tt.expected, got)
+		}
+	}
+}
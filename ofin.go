@@ -0,0 +1,108 @@
+// Package ofin runs ofin scripts from Go programs and tests.
+package ofin
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/itsert/ofin/script/callable"
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/resolver"
+	"github.com/itsert/ofin/script/result"
+)
+
+// DefaultFile names a script run from source that was given no file name.
+const DefaultFile = "<script>"
+
+// Options configures how a script is run.
+type Options struct {
+	// File names the script in errors and results. Scripts it imports are
+	// found relative to it. It defaults to DefaultFile.
+	File string
+	// ContinueOnFailure keeps a scenario running after one of its
+	// assertions fails instead of skipping its remaining steps.
+	ContinueOnFailure bool
+	// Natives are defined in the global environment on top of the
+	// built-in ones, which they may replace.
+	Natives *callable.Registry
+	// Output receives what the script prints outside of any scenario;
+	// what a scenario prints is kept in its result. It defaults to
+	// os.Stdout.
+	Output io.Writer
+	// Diagnostics receives messages about the interpreter itself. It
+	// defaults to os.Stderr.
+	Diagnostics io.Writer
+	// ScenarioTimeout, when set, errors any scenario still running after
+	// it. The run as a whole is bounded by the context.
+	ScenarioTimeout time.Duration
+	// Loader parses the script and the scripts it imports. Pass one to
+	// render the errors of a run with its Diagnostic, which quotes each
+	// against the script it was raised in. It defaults to a new loader.
+	Loader *loader.Loader
+}
+
+// Run interprets source and returns the outcome of its scenarios.
+//
+// The error is a merror.ErrorList when the script, or one it imports, has
+// syntax errors, in which case nothing is run and the results are nil. It is
+// a *merror.RuntimeError when a runtime error is raised outside of any
+// scenario; the results then cover the scenarios run until then. A failing
+// or errored scenario is not an error: it is recorded in the results.
+//
+// When ctx is done while the script runs, the scenario running is errored
+// at the line it was executing and the same *merror.RuntimeError is returned.
+func Run(ctx context.Context, source string, opts Options) (*result.Run, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.File == "" {
+		opts.File = DefaultFile
+	}
+	if opts.Loader == nil {
+		opts.Loader = loader.NewLoader()
+	}
+	module, err := opts.Loader.LoadSource(opts.File, source)
+	if err != nil {
+		return nil, err
+	}
+
+	i := interpreter.NewInterpreter()
+	i.File = module.File
+	i.Capture = true
+	i.ScenarioTimeout = opts.ScenarioTimeout
+	if opts.Output != nil {
+		i.Output = opts.Output
+	}
+	if opts.Diagnostics != nil {
+		i.Diagnostics = opts.Diagnostics
+	}
+	if opts.Natives != nil {
+		opts.Natives.Define(i.Global)
+	}
+	if opts.ContinueOnFailure {
+		i.Policy = interpreter.ContinueScenario
+	}
+	if err := resolver.NewResolver(i, module.File).Resolve(module.Statements); err != nil {
+		return nil, err
+	}
+
+	err = i.Interpret(ctx, module.Statements)
+	results := i.Results()
+	results.File = opts.File
+	return results, err
+}
+
+// RunFile is Run for the script at path. opts.File defaults to path.
+func RunFile(ctx context.Context, path string, opts Options) (*result.Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.File == "" {
+		opts.File = path
+	}
+	return Run(ctx, string(data), opts)
+}
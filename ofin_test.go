@@ -0,0 +1,123 @@
+package ofin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/callable"
+	"github.com/itsert/ofin/script/result"
+)
+
+func TestRun(t *testing.T) {
+	source := `Scenario "passes":
+    Given a = 1
+    Then a == 1
+Scenario "fails":
+    Given a = 1
+    print "checking"
+    Then a == 2
+Scenario "errors":
+    Given a = 1
+    Then a.b == 2
+`
+	var out bytes.Buffer
+	run, err := Run(context.Background(), source, Options{File: "run.ac", Output: &out})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if run.Scenarios[1].Output != "checking\n" || out.Len() != 0 {
+		t.Fatalf("output wrong. expected it captured, got=%q and %q", run.Scenarios[1].Output, out.String())
+	}
+	if run.File != "run.ac" {
+		t.Fatalf("file wrong. expected=%q, got=%q", "run.ac", run.File)
+	}
+	expected := []result.Status{result.PASSED, result.FAILED, result.ERRORED}
+	if len(run.Scenarios) != len(expected) {
+		t.Fatalf("scenarios wrong. expected=%d, got=%d", len(expected), len(run.Scenarios))
+	}
+	for i, status := range expected {
+		if run.Scenarios[i].Status != status {
+			t.Fatalf("scenarios[%d] - status wrong. expected=%q, got=%q", i, status, run.Scenarios[i].Status)
+		}
+	}
+}
+
+func TestRunWithErrors(t *testing.T) {
+	run, err := Run(context.Background(), "Given a = (1\n", Options{})
+	errors, ok := err.(merror.ErrorList)
+	if !ok || run != nil {
+		t.Fatalf("expected an ErrorList and no results, got=%#v %v", err, run)
+	}
+	if errors[0].File != DefaultFile {
+		t.Fatalf("file wrong. expected=%q, got=%q", DefaultFile, errors[0].File)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, "Given a = 1\n", Options{}); err != context.Canceled {
+		t.Fatalf("error wrong. expected=%v, got=%v", context.Canceled, err)
+	}
+}
+
+func TestRunWithNatives(t *testing.T) {
+	natives := callable.NewRegistry()
+	natives.MustRegister("status", func(path string) (int, error) {
+		if path != "/health" {
+			return 0, fmt.Errorf("no route to %s", path)
+		}
+		return 200, nil
+	})
+	source := `Scenario "healthy":
+    Given s = status("/health")
+    Then s == 200
+Scenario "missing":
+    Given s = status("/nope")
+`
+	run, err := Run(context.Background(), source, Options{Natives: natives})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if run.Scenarios[0].Status != result.PASSED {
+		t.Fatalf("scenarios[0] - status wrong. expected=%q, got=%q", result.PASSED, run.Scenarios[0].Status)
+	}
+	if run.Scenarios[1].Status != result.ERRORED || !strings.Contains(run.Scenarios[1].ErrorMessage(), "no route to /nope") {
+		t.Fatalf("scenarios[1] wrong. expected an error, got=%q %q", run.Scenarios[1].Status, run.Scenarios[1].ErrorMessage())
+	}
+}
+
+func TestRunWithTimeouts(t *testing.T) {
+	source := `fn spin():
+    Given i = 0
+    while true:
+        i = i + 1
+Scenario "hangs":
+    When spin()
+`
+	run, err := Run(context.Background(), source, Options{ScenarioTimeout: 10 * time.Millisecond})
+	if err != nil || run.Scenarios[0].Status != result.ERRORED {
+		t.Fatalf("expected the scenario to error, got=%v %v", err, run.Scenarios[0].Status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	run, err = Run(ctx, source, Options{})
+	runtimeError, ok := err.(*merror.RuntimeError)
+	if !ok || runtimeError.Line != 3 || run.Scenarios[0].ErrorMessage() != "<script>:3:5 run timed out" {
+		t.Fatalf("expected the run to time out at line 3, got=%v", err)
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	run, err := RunFile(context.Background(), "testdata/arithmetic.ac", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if run.File != "testdata/arithmetic.ac" || len(run.Scenarios) != 2 || run.Failed() {
+		t.Fatalf("run wrong. got=%+v", run)
+	}
+}
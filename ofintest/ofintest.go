@@ -0,0 +1,69 @@
+// Package ofintest runs ofin scripts as Go tests. It is kept out of package
+// ofin so that programs embedding ofin do not link in package testing.
+package ofintest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itsert/ofin"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/result"
+)
+
+// RunTests runs every script matching pattern as a subtest of t named after
+// the file, itself with a subtest per scenario. A failed or errored scenario
+// fails its subtest and a skipped one is skipped, so `go test -run` can pick
+// out single scenarios. What a scenario prints is logged to its subtest, so
+// go test shows it when the scenario fails or with -v.
+func RunTests(t *testing.T, pattern string, opts ofin.Options) {
+	t.Helper()
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatalf("ofin: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("ofin: no scripts match %s", pattern)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			runTest(t, file, opts)
+		})
+	}
+}
+
+func runTest(t *testing.T, file string, opts ofin.Options) {
+	t.Helper()
+	modules := loader.NewLoader()
+	opts.File = file
+	opts.Loader = modules
+	run, err := ofin.RunFile(context.Background(), file, opts)
+	if run == nil {
+		t.Fatalf("ofin: %s", modules.Diagnostic(err))
+	}
+
+	for _, scenario := range run.Scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			if scenario.Output != "" {
+				t.Log(strings.TrimSuffix(scenario.Output, "\n"))
+			}
+			switch scenario.Status {
+			case result.FAILED:
+				for _, failure := range scenario.Failures() {
+					t.Errorf("%s: %s", file, failure.Error())
+				}
+			case result.ERRORED:
+				t.Errorf("%s: %s", file, scenario.ErrorMessage())
+			case result.SKIPPED:
+				t.Skip("skipped")
+			}
+		})
+	}
+	if err != nil {
+		t.Errorf("ofin: %s", modules.Diagnostic(err))
+	}
+}
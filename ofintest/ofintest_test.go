@@ -0,0 +1,11 @@
+package ofintest
+
+import (
+	"testing"
+
+	"github.com/itsert/ofin"
+)
+
+func TestRunTests(t *testing.T) {
+	RunTests(t, "../testdata/*.ac", ofin.Options{})
+}
@@ -4,10 +4,13 @@ import "github.com/itsert/ofin/script/token"
 
 type Expression interface {
 	Expression()
+	Span() token.Span
+	SetSpan(span token.Span)
 	Accept(visitor ExpressionVisitor) interface{}
 }
 
 type Assign struct {
+	Spanned
 	Name token.Token
 	Expr Expression
 }
@@ -26,6 +29,7 @@ func (a *Assign) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Binary struct {
+	Spanned
 	Left     Expression
 	Operator token.Token
 	Right    Expression
@@ -46,6 +50,7 @@ func (b *Binary) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Call struct {
+	Spanned
 	Callee    Expression
 	Paren     token.Token
 	Arguments []Expression
@@ -66,6 +71,7 @@ func (c *Call) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Grouping struct {
+	Spanned
 	Expr Expression
 }
 
@@ -82,6 +88,7 @@ func (g *Grouping) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Literal struct {
+	Spanned
 	Value interface{}
 }
 
@@ -98,6 +105,7 @@ func (l *Literal) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Logical struct {
+	Spanned
 	Left     Expression
 	Operator token.Token
 	Right    Expression
@@ -118,6 +126,7 @@ func (l *Logical) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Unary struct {
+	Spanned
 	Operator token.Token
 	Right    Expression
 }
@@ -136,6 +145,7 @@ func (u *Unary) Accept(visitor ExpressionVisitor) interface{} {
 }
 
 type Variable struct {
+	Spanned
 	Name token.Token
 }
 
@@ -151,6 +161,130 @@ func (v *Variable) Accept(visitor ExpressionVisitor) interface{} {
 	return visitor.VisitVariableExpression(v)
 }
 
+type List struct {
+	Spanned
+	Bracket  token.Token
+	Elements []Expression
+}
+
+func NewList(Bracket token.Token, Elements []Expression) *List {
+	return &List{
+		Bracket:  Bracket,
+		Elements: Elements,
+	}
+}
+
+func (l *List) Expression() {}
+
+func (l *List) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitListExpression(l)
+}
+
+type Map struct {
+	Spanned
+	Brace  token.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+func NewMap(Brace token.Token, Keys []Expression, Values []Expression) *Map {
+	return &Map{
+		Brace:  Brace,
+		Keys:   Keys,
+		Values: Values,
+	}
+}
+
+func (m *Map) Expression() {}
+
+func (m *Map) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitMapExpression(m)
+}
+
+type Index struct {
+	Spanned
+	Object  Expression
+	Bracket token.Token
+	Key     Expression
+}
+
+func NewIndex(Object Expression, Bracket token.Token, Key Expression) *Index {
+	return &Index{
+		Object:  Object,
+		Bracket: Bracket,
+		Key:     Key,
+	}
+}
+
+func (i *Index) Expression() {}
+
+func (i *Index) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitIndexExpression(i)
+}
+
+type SetIndex struct {
+	Spanned
+	Object  Expression
+	Bracket token.Token
+	Key     Expression
+	Value   Expression
+}
+
+func NewSetIndex(Object Expression, Bracket token.Token, Key Expression, Value Expression) *SetIndex {
+	return &SetIndex{
+		Object:  Object,
+		Bracket: Bracket,
+		Key:     Key,
+		Value:   Value,
+	}
+}
+
+func (s *SetIndex) Expression() {}
+
+func (s *SetIndex) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitSetIndexExpression(s)
+}
+
+type Get struct {
+	Spanned
+	Object Expression
+	Name   token.Token
+}
+
+func NewGet(Object Expression, Name token.Token) *Get {
+	return &Get{
+		Object: Object,
+		Name:   Name,
+	}
+}
+
+func (g *Get) Expression() {}
+
+func (g *Get) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitGetExpression(g)
+}
+
+type Set struct {
+	Spanned
+	Object Expression
+	Name   token.Token
+	Value  Expression
+}
+
+func NewSet(Object Expression, Name token.Token, Value Expression) *Set {
+	return &Set{
+		Object: Object,
+		Name:   Name,
+		Value:  Value,
+	}
+}
+
+func (s *Set) Expression() {}
+
+func (s *Set) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.VisitSetExpression(s)
+}
+
 type ExpressionVisitor interface {
 	VisitAssignExpression(expression *Assign) interface{}
 	VisitBinaryExpression(expression *Binary) interface{}
@@ -160,4 +294,10 @@ type ExpressionVisitor interface {
 	VisitLogicalExpression(expression *Logical) interface{}
 	VisitUnaryExpression(expression *Unary) interface{}
 	VisitVariableExpression(expression *Variable) interface{}
+	VisitListExpression(expression *List) interface{}
+	VisitMapExpression(expression *Map) interface{}
+	VisitIndexExpression(expression *Index) interface{}
+	VisitSetIndexExpression(expression *SetIndex) interface{}
+	VisitGetExpression(expression *Get) interface{}
+	VisitSetExpression(expression *Set) interface{}
 }
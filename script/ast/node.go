@@ -1,5 +1,21 @@
 package ast
 
+import "github.com/itsert/ofin/script/token"
+
 type Node interface {
 	Literal() string
 }
+
+// Spanned is embedded in every expression and statement to record the part
+// of the script it was parsed from.
+type Spanned struct {
+	span token.Span
+}
+
+func (s *Spanned) Span() token.Span {
+	return s.span
+}
+
+func (s *Spanned) SetSpan(span token.Span) {
+	s.span = span
+}
@@ -1 +1,8 @@
 package ast
+
+// Module is a parsed script. The loader attaches the module an import
+// statement names to that statement.
+type Module struct {
+	File       string
+	Statements []Statement
+}
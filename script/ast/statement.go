@@ -4,10 +4,13 @@ import "github.com/itsert/ofin/script/token"
 import "github.com/itsert/ofin/script/environment"
 type Statement interface {
 	Statement()
+	Span() token.Span
+	SetSpan(span token.Span)
 	Accept(visitor StatementVisitor) interface{}
 }
 
 type StmtExpression struct {
+	Spanned
 	Expr Expression
 }
 
@@ -25,6 +28,7 @@ func (s *StmtExpression) Accept(visitor StatementVisitor) interface{} {
 
 
 type If struct {
+	Spanned
 	Condition Expression
 	ThenBranch Statement
 	ElseBranch Statement
@@ -46,6 +50,7 @@ func (i *If) Accept(visitor StatementVisitor) interface{} {
 
 
 type Print struct {
+	Spanned
 	Expr Expression
 }
 
@@ -63,12 +68,17 @@ func (p *Print) Accept(visitor StatementVisitor) interface{} {
 
 
 type When struct {
+	Spanned
+	Keyword token.Token
 	Expr Expression
+	Body Statement
 }
 
-func NewWhen(Expr Expression) *When{
+func NewWhen(Keyword token.Token, Expr Expression, Body Statement) *When{
 	return &When{
+		Keyword:	Keyword,
 		Expr:	Expr,
+		Body:	Body,
 	}
 }
 
@@ -80,12 +90,17 @@ func (w *When) Accept(visitor StatementVisitor) interface{} {
 
 
 type Then struct {
+	Spanned
+	Keyword token.Token
 	Expr Expression
+	Body Statement
 }
 
-func NewThen(Expr Expression) *Then{
+func NewThen(Keyword token.Token, Expr Expression, Body Statement) *Then{
 	return &Then{
+		Keyword:	Keyword,
 		Expr:	Expr,
+		Body:	Body,
 	}
 }
 
@@ -97,11 +112,14 @@ func (t *Then) Accept(visitor StatementVisitor) interface{} {
 
 
 type And struct {
+	Spanned
+	Keyword token.Token
 	Expr Expression
 }
 
-func NewAnd(Expr Expression) *And{
+func NewAnd(Keyword token.Token, Expr Expression) *And{
 	return &And{
+		Keyword:	Keyword,
 		Expr:	Expr,
 	}
 }
@@ -114,11 +132,14 @@ func (a *And) Accept(visitor StatementVisitor) interface{} {
 
 
 type Scenario struct {
+	Spanned
+	Keyword token.Token
 	Label string
 }
 
-func NewScenario(Label string) *Scenario{
+func NewScenario(Keyword token.Token, Label string) *Scenario{
 	return &Scenario{
+		Keyword:	Keyword,
 		Label:	Label,
 	}
 }
@@ -131,12 +152,15 @@ func (s *Scenario) Accept(visitor StatementVisitor) interface{} {
 
 
 type Var struct {
+	Spanned
+	Keyword token.Token
 	Name token.Token
 	Initializer Expression
 }
 
-func NewVar(Name token.Token, Initializer Expression) *Var{
+func NewVar(Keyword token.Token, Name token.Token, Initializer Expression) *Var{
 	return &Var{
+		Keyword:	Keyword,
 		Name:	Name,
 		Initializer:	Initializer,
 	}
@@ -150,6 +174,7 @@ func (v *Var) Accept(visitor StatementVisitor) interface{} {
 
 
 type While struct {
+	Spanned
 	Condition Expression
 	Body Statement
 }
@@ -169,6 +194,7 @@ func (w *While) Accept(visitor StatementVisitor) interface{} {
 
 
 type Block struct {
+	Spanned
 	Statements []Statement
 	BlockState environment.State
 }
@@ -188,6 +214,7 @@ func (b *Block) Accept(visitor StatementVisitor) interface{} {
 
 
 type DoNoting struct {
+	Spanned
 	Name token.Token
 }
 
@@ -204,6 +231,94 @@ func (d *DoNoting) Accept(visitor StatementVisitor) interface{} {
 }
 
 
+type Function struct {
+	Spanned
+	Name token.Token
+	Params []token.Token
+	Defaults []Expression
+	Body []Statement
+}
+
+func NewFunction(Name token.Token, Params []token.Token, Defaults []Expression, Body []Statement) *Function{
+	return &Function{
+		Name:	Name,
+		Params:	Params,
+		Defaults:	Defaults,
+		Body:	Body,
+	}
+}
+
+func (f *Function) Statement() {}
+
+func (f *Function) Accept(visitor StatementVisitor) interface{} {
+	 return visitor.VisitFunctionStatement(f)
+}
+
+
+type Return struct {
+	Spanned
+	Keyword token.Token
+	Value Expression
+}
+
+func NewReturn(Keyword token.Token, Value Expression) *Return{
+	return &Return{
+		Keyword:	Keyword,
+		Value:	Value,
+	}
+}
+
+func (r *Return) Statement() {}
+
+func (r *Return) Accept(visitor StatementVisitor) interface{} {
+	 return visitor.VisitReturnStatement(r)
+}
+
+
+type For struct {
+	Spanned
+	Name token.Token
+	Iterable Expression
+	Body Statement
+}
+
+func NewFor(Name token.Token, Iterable Expression, Body Statement) *For{
+	return &For{
+		Name:	Name,
+		Iterable:	Iterable,
+		Body:	Body,
+	}
+}
+
+func (f *For) Statement() {}
+
+func (f *For) Accept(visitor StatementVisitor) interface{} {
+	 return visitor.VisitForStatement(f)
+}
+
+
+type Import struct {
+	Spanned
+	Keyword token.Token
+	Path token.Token
+	Module *Module
+}
+
+func NewImport(Keyword token.Token, Path token.Token, Module *Module) *Import{
+	return &Import{
+		Keyword:	Keyword,
+		Path:	Path,
+		Module:	Module,
+	}
+}
+
+func (i *Import) Statement() {}
+
+func (i *Import) Accept(visitor StatementVisitor) interface{} {
+	 return visitor.VisitImportStatement(i)
+}
+
+
 type StatementVisitor interface {
 	VisitStmtExpressionStatement(statement *StmtExpression) interface{}
 	VisitIfStatement(statement *If) interface{}
@@ -216,5 +331,9 @@ type StatementVisitor interface {
 	VisitWhileStatement(statement *While) interface{}
 	VisitBlockStatement(statement *Block) interface{}
 	VisitDoNotingStatement(statement *DoNoting) interface{}
+	VisitFunctionStatement(statement *Function) interface{}
+	VisitReturnStatement(statement *Return) interface{}
+	VisitForStatement(statement *For) interface{}
+	VisitImportStatement(statement *Import) interface{}
 }
 
@@ -1,8 +1,61 @@
 package callable
 
-import "github.com/itsert/ofin/script/environment"
+import (
+	"fmt"
 
+	"github.com/itsert/ofin/script/environment"
+)
+
+// Callable is implemented by every value that can be called. Call is only
+// handed a number of arguments its Arity accepts; when given fewer than
+// the maximum, it supplies the defaults of the missing ones itself.
 type Callable interface {
-	Arity() int
+	Arity() Arity
 	Call(env *environment.Environment, arguments []interface{}) interface{}
 }
+
+// Variadic is the Max of an Arity with no upper bound.
+const Variadic = -1
+
+// Arity is the number of arguments a Callable accepts: at least Min and at
+// most Max, unless Max is Variadic.
+type Arity struct {
+	Min int
+	Max int
+}
+
+// Exactly is the Arity of a Callable that takes n arguments.
+func Exactly(n int) Arity {
+	return Arity{Min: n, Max: n}
+}
+
+func (a Arity) Accepts(n int) bool {
+	return n >= a.Min && (a.Max == Variadic || n <= a.Max)
+}
+
+// String describes the arity for error messages.
+func (a Arity) String() string {
+	switch {
+	case a.Max == Variadic:
+		return fmt.Sprintf("at least %d", a.Min)
+	case a.Min == a.Max:
+		return fmt.Sprintf("%d", a.Min)
+	}
+	return fmt.Sprintf("%d to %d", a.Min, a.Max)
+}
+
+// NativeError is panicked by a native function that cannot handle the
+// arguments it was given; the interpreter reports it against the call site.
+type NativeError struct {
+	Message string
+}
+
+func NewNativeError(message string) *NativeError {
+	return &NativeError{
+		Message: message,
+	}
+}
+
+func (e *NativeError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,32 @@
+package callable
+
+import (
+	"unicode/utf8"
+
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/object"
+)
+
+type Len struct{}
+
+func NewLen() Len {
+	return Len{}
+}
+
+func (l Len) Arity() Arity {
+	return Exactly(1)
+}
+
+func (l Len) Call(env *environment.Environment, arguments []interface{}) interface{} {
+	switch v := arguments[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v))
+	case object.Sized:
+		return float64(v.Len())
+	}
+	panic(NewNativeError("len expects a string, list or map"))
+}
+
+func (l Len) String() string {
+	return "<native fn len>"
+}
@@ -0,0 +1,175 @@
+package callable
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/object"
+)
+
+// Method is a native function bound to the value it was looked up on, the
+// result of `s.upper` before it is called.
+type Method struct {
+	name  string
+	arity int
+	fn    func(arguments []interface{}) interface{}
+}
+
+func (m *Method) Arity() Arity {
+	return Exactly(m.arity)
+}
+
+func (m *Method) Call(env *environment.Environment, arguments []interface{}) interface{} {
+	return m.fn(arguments)
+}
+
+func (m *Method) String() string {
+	return fmt.Sprintf("<method %s>", m.name)
+}
+
+type stringMethod struct {
+	arity int
+	fn    func(s string, arguments []interface{}) interface{}
+}
+
+type listMethod struct {
+	arity int
+	fn    func(l *object.List, arguments []interface{}) interface{}
+}
+
+type mapMethod struct {
+	arity int
+	fn    func(m *object.Map, arguments []interface{}) interface{}
+}
+
+var stringMethods = map[string]stringMethod{
+	"upper": {0, func(s string, arguments []interface{}) interface{} {
+		return strings.ToUpper(s)
+	}},
+	"lower": {0, func(s string, arguments []interface{}) interface{} {
+		return strings.ToLower(s)
+	}},
+	"trim": {0, func(s string, arguments []interface{}) interface{} {
+		return strings.TrimSpace(s)
+	}},
+	"len": {0, func(s string, arguments []interface{}) interface{} {
+		return float64(len([]rune(s)))
+	}},
+	"contains": {1, func(s string, arguments []interface{}) interface{} {
+		return strings.Contains(s, stringArgument("contains", arguments, 0))
+	}},
+	"starts_with": {1, func(s string, arguments []interface{}) interface{} {
+		return strings.HasPrefix(s, stringArgument("starts_with", arguments, 0))
+	}},
+	"ends_with": {1, func(s string, arguments []interface{}) interface{} {
+		return strings.HasSuffix(s, stringArgument("ends_with", arguments, 0))
+	}},
+	"replace": {2, func(s string, arguments []interface{}) interface{} {
+		return strings.ReplaceAll(s, stringArgument("replace", arguments, 0), stringArgument("replace", arguments, 1))
+	}},
+	"split": {1, func(s string, arguments []interface{}) interface{} {
+		parts := []interface{}{}
+		for _, part := range strings.Split(s, stringArgument("split", arguments, 0)) {
+			parts = append(parts, part)
+		}
+		return object.NewList(parts)
+	}},
+}
+
+var listMethods = map[string]listMethod{
+	"len": {0, func(l *object.List, arguments []interface{}) interface{} {
+		return float64(l.Len())
+	}},
+	"append": {1, func(l *object.List, arguments []interface{}) interface{} {
+		l.Elements = append(l.Elements, arguments[0])
+		return l
+	}},
+	"pop": {0, func(l *object.List, arguments []interface{}) interface{} {
+		if len(l.Elements) == 0 {
+			panic(NewNativeError("pop from empty list"))
+		}
+		last := l.Elements[len(l.Elements)-1]
+		l.Elements = l.Elements[:len(l.Elements)-1]
+		return last
+	}},
+	"contains": {1, func(l *object.List, arguments []interface{}) interface{} {
+		for _, element := range l.Elements {
+			if object.Equal(element, arguments[0]) {
+				return true
+			}
+		}
+		return false
+	}},
+	"join": {1, func(l *object.List, arguments []interface{}) interface{} {
+		parts := []string{}
+		for _, element := range l.Elements {
+			parts = append(parts, fmt.Sprintf("%v", element))
+		}
+		return strings.Join(parts, stringArgument("join", arguments, 0))
+	}},
+}
+
+var mapMethods = map[string]mapMethod{
+	"len": {0, func(m *object.Map, arguments []interface{}) interface{} {
+		return float64(m.Len())
+	}},
+	"keys": {0, func(m *object.Map, arguments []interface{}) interface{} {
+		return object.NewList(m.Keys())
+	}},
+	"values": {0, func(m *object.Map, arguments []interface{}) interface{} {
+		values := []interface{}{}
+		for _, key := range m.Keys() {
+			value, _ := m.Get(key)
+			values = append(values, value)
+		}
+		return object.NewList(values)
+	}},
+	"has": {1, func(m *object.Map, arguments []interface{}) interface{} {
+		if !object.IsHashable(arguments[0]) {
+			return false
+		}
+		_, ok := m.Get(arguments[0])
+		return ok
+	}},
+	"get": {1, func(m *object.Map, arguments []interface{}) interface{} {
+		if !object.IsHashable(arguments[0]) {
+			return nil
+		}
+		value, _ := m.Get(arguments[0])
+		return value
+	}},
+}
+
+// MethodOf looks up the built-in method name on receiver and binds it.
+func MethodOf(receiver interface{}, name string) (*Method, bool) {
+	switch r := receiver.(type) {
+	case string:
+		if m, ok := stringMethods[name]; ok {
+			return &Method{name, m.arity, func(arguments []interface{}) interface{} {
+				return m.fn(r, arguments)
+			}}, true
+		}
+	case *object.List:
+		if m, ok := listMethods[name]; ok {
+			return &Method{name, m.arity, func(arguments []interface{}) interface{} {
+				return m.fn(r, arguments)
+			}}, true
+		}
+	case *object.Map:
+		if m, ok := mapMethods[name]; ok {
+			return &Method{name, m.arity, func(arguments []interface{}) interface{} {
+				return m.fn(r, arguments)
+			}}, true
+		}
+	}
+	return nil, false
+}
+
+func stringArgument(method string, arguments []interface{}, i int) string {
+	s, ok := arguments[i].(string)
+	if !ok {
+		panic(NewNativeError(fmt.Sprintf("%s expects a string argument", method)))
+	}
+	return s
+}
@@ -0,0 +1,254 @@
+package callable
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/object"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Native is a Go function made callable from scripts. Arguments are
+// converted from script values to the types of its parameters, and its
+// result back to a script value. A trailing error result that is not nil
+// becomes a runtime error at the call site.
+type Native struct {
+	name string
+	fn   reflect.Value
+	// params are the types of the fixed parameters.
+	params []reflect.Type
+	// variadic is the element type of the final ...parameter, nil when
+	// there is none.
+	variadic reflect.Type
+	// value and err report which results the function has.
+	value bool
+	err   bool
+}
+
+// NewNative wraps fn, which must be a function returning nothing, a
+// value, an error, or a value and an error.
+func NewNative(name string, fn interface{}) (*Native, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("native %s must be a function, got %T", name, fn)
+	}
+	t := v.Type()
+	n := &Native{name: name, fn: v}
+	for i := 0; i < t.NumIn(); i++ {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			n.variadic = t.In(i).Elem()
+			break
+		}
+		n.params = append(n.params, t.In(i))
+	}
+	switch {
+	case t.NumOut() == 0:
+	case t.NumOut() == 1 && t.Out(0) == errorType:
+		n.err = true
+	case t.NumOut() == 1:
+		n.value = true
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+		n.value, n.err = true, true
+	default:
+		return nil, fmt.Errorf("native %s must return at most a value and an error", name)
+	}
+	return n, nil
+}
+
+func (n *Native) Arity() Arity {
+	if n.variadic != nil {
+		return Arity{Min: len(n.params), Max: Variadic}
+	}
+	return Exactly(len(n.params))
+}
+
+func (n *Native) Call(env *environment.Environment, arguments []interface{}) interface{} {
+	in := make([]reflect.Value, len(arguments))
+	for i, argument := range arguments {
+		t := n.variadic
+		if i < len(n.params) {
+			t = n.params[i]
+		}
+		v, err := toGo(argument, t)
+		if err != nil {
+			panic(NewNativeError(fmt.Sprintf("%s: argument %d %s", n.name, i+1, err)))
+		}
+		in[i] = v
+	}
+	out := n.fn.Call(in)
+	if n.err && !out[len(out)-1].IsNil() {
+		panic(NewNativeError(out[len(out)-1].Interface().(error).Error()))
+	}
+	if !n.value {
+		return nil
+	}
+	return toScript(out[0])
+}
+
+func (n *Native) String() string {
+	return fmt.Sprintf("<native fn %s>", n.name)
+}
+
+// toGo converts a script value to a Go value of type t.
+func toGo(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("must be %s, got nil", kindName(t))
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v.Convert(t), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := value.(float64)
+		if !ok {
+			break
+		}
+		out := reflect.New(t).Elem()
+		if f != math.Trunc(f) {
+			return reflect.Value{}, fmt.Errorf("must be a whole number, got %v", f)
+		}
+		// Check the bounds while f is still a float64; converting first
+		// wraps or saturates values too large for 64 bits.
+		unsigned := t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64
+		min, max := -math.Ldexp(1, t.Bits()-1), math.Ldexp(1, t.Bits()-1)
+		if unsigned {
+			min, max = 0, math.Ldexp(1, t.Bits())
+		}
+		if f < min || f >= max {
+			return reflect.Value{}, fmt.Errorf("is out of range, got %v", f)
+		}
+		if unsigned {
+			out.SetUint(uint64(f))
+		} else {
+			out.SetInt(int64(f))
+		}
+		return out, nil
+	case reflect.Float32, reflect.Float64:
+		if f, ok := value.(float64); ok {
+			return reflect.ValueOf(f).Convert(t), nil
+		}
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+	case reflect.Slice:
+		l, ok := value.(*object.List)
+		if !ok {
+			break
+		}
+		out := reflect.MakeSlice(t, len(l.Elements), len(l.Elements))
+		for i, element := range l.Elements {
+			e, err := toGo(element, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d %s", i, err)
+			}
+			out.Index(i).Set(e)
+		}
+		return out, nil
+	case reflect.Map:
+		m, ok := value.(*object.Map)
+		if !ok {
+			break
+		}
+		out := reflect.MakeMapWithSize(t, m.Len())
+		for _, key := range m.Keys() {
+			k, err := toGo(key, t.Key())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %s %s", object.Inspect(key), err)
+			}
+			element, _ := m.Get(key)
+			e, err := toGo(element, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("value of %s %s", object.Inspect(key), err)
+			}
+			out.SetMapIndex(k, e)
+		}
+		return out, nil
+	}
+	return reflect.Value{}, fmt.Errorf("must be %s, got %s", kindName(t), object.Inspect(value))
+}
+
+// toScript converts a Go value to a script value. Numbers become float64,
+// slices and arrays lists, and maps maps; other values are passed through
+// for scripts to hand back to natives.
+func toScript(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return toScript(v.Elem())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		elements := make([]interface{}, v.Len())
+		for i := range elements {
+			elements[i] = toScript(v.Index(i))
+		}
+		return object.NewList(elements)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		// Go maps are unordered; sort the keys so results are repeatable.
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		m := object.NewMap()
+		for _, key := range keys {
+			m.Set(toScript(key), toScript(v.MapIndex(key)))
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// kindName describes the script values accepted for t.
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map:
+		return "a map"
+	}
+	return t.String()
+}
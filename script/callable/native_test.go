@@ -0,0 +1,71 @@
+package callable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestToGoIntegers(t *testing.T) {
+	tests := []struct {
+		value    float64
+		t        reflect.Type
+		expected interface{}
+		err      string
+	}{
+		{42, reflect.TypeOf(int(0)), int(42), ""},
+		{-128, reflect.TypeOf(int8(0)), int8(-128), ""},
+		{127, reflect.TypeOf(int8(0)), int8(127), ""},
+		{128, reflect.TypeOf(int8(0)), nil, "is out of range, got 128"},
+		{-129, reflect.TypeOf(int8(0)), nil, "is out of range, got -129"},
+		{255, reflect.TypeOf(uint8(0)), uint8(255), ""},
+		{256, reflect.TypeOf(uint8(0)), nil, "is out of range, got 256"},
+		{-1, reflect.TypeOf(uint(0)), nil, "is out of range, got -1"},
+		{-9223372036854775808, reflect.TypeOf(int64(0)), int64(math.MinInt64), ""},
+		{9223372036854775808, reflect.TypeOf(int64(0)), nil, "is out of range, got 9.223372036854776e+18"},
+		{1e22, reflect.TypeOf(int64(0)), nil, "is out of range, got 1e+22"},
+		{-1e22, reflect.TypeOf(int64(0)), nil, "is out of range, got -1e+22"},
+		{1 << 63, reflect.TypeOf(uint64(0)), uint64(1 << 63), ""},
+		{18446744073709551616, reflect.TypeOf(uint64(0)), nil, "is out of range, got 1.8446744073709552e+19"},
+		{1e20, reflect.TypeOf(uint64(0)), nil, "is out of range, got 1e+20"},
+		{math.Inf(1), reflect.TypeOf(int(0)), nil, "is out of range, got +Inf"},
+		{1.5, reflect.TypeOf(int(0)), nil, "must be a whole number, got 1.5"},
+		{-0.5, reflect.TypeOf(uint(0)), nil, "must be a whole number, got -0.5"},
+		{math.NaN(), reflect.TypeOf(int(0)), nil, "must be a whole number, got NaN"},
+	}
+	for i, tt := range tests {
+		v, err := toGo(tt.value, tt.t)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("tests[%d] - error wrong. expected=%q, got=%v", i, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error %v", i, err)
+		}
+		if v.Interface() != tt.expected {
+			t.Fatalf("tests[%d] - value wrong. expected=%v (%T), got=%v (%T)",
+				i, tt.expected, tt.expected, v.Interface(), v.Interface())
+		}
+	}
+}
+
+func TestNativeCallOutOfRange(t *testing.T) {
+	native, err := NewNative("u64", func(x uint64) uint64 { return x })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer func() {
+		r := recover()
+		nativeError, ok := r.(*NativeError)
+		if !ok {
+			t.Fatalf("panic wrong. expected=*NativeError, got=%T (%v)", r, r)
+		}
+		if nativeError.Error() != "u64: argument 1 is out of range, got 1e+20" {
+			t.Fatalf("error wrong. got=%q", nativeError.Error())
+		}
+	}()
+	native.Call(nil, []interface{}{1e20})
+	t.Fatalf("expected u64(1e20) to fail")
+}
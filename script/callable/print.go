@@ -0,0 +1,36 @@
+package callable
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/itsert/ofin/script/environment"
+)
+
+// PrintAll prints its arguments on one line, separated by spaces, the way
+// the print statement prints a single value.
+type PrintAll struct {
+	out io.Writer
+}
+
+func NewPrintAll(out io.Writer) PrintAll {
+	return PrintAll{out: out}
+}
+
+func (p PrintAll) Arity() Arity {
+	return Arity{Min: 0, Max: Variadic}
+}
+
+func (p PrintAll) Call(env *environment.Environment, arguments []interface{}) interface{} {
+	parts := make([]string, len(arguments))
+	for i, argument := range arguments {
+		parts[i] = fmt.Sprintf("%+v", argument)
+	}
+	fmt.Fprintln(p.out, strings.Join(parts, " "))
+	return nil
+}
+
+func (p PrintAll) String() string {
+	return "<native fn print_all>"
+}
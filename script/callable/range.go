@@ -0,0 +1,40 @@
+package callable
+
+import (
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/object"
+)
+
+// Range is range(stop), counting from 0, range(start, stop), or
+// range(start, stop, step).
+type Range struct{}
+
+func NewRange() Range {
+	return Range{}
+}
+
+func (r Range) Arity() Arity {
+	return Arity{Min: 1, Max: 3}
+}
+
+func (r Range) Call(env *environment.Environment, arguments []interface{}) interface{} {
+	numbers := []float64{0, 0, 1}
+	for i, argument := range arguments {
+		n, ok := argument.(float64)
+		if !ok {
+			panic(NewNativeError("range expects numbers"))
+		}
+		numbers[i] = n
+	}
+	if len(arguments) == 1 {
+		numbers[0], numbers[1] = 0, numbers[0]
+	}
+	if numbers[2] == 0 {
+		panic(NewNativeError("range step must not be zero"))
+	}
+	return object.NewRange(numbers[0], numbers[1], numbers[2])
+}
+
+func (r Range) String() string {
+	return "<native fn range>"
+}
@@ -0,0 +1,89 @@
+package callable
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/token"
+)
+
+// Registry holds native functions by name, to be defined in the global
+// environment of an interpreter. Embedders register their own Go functions
+// on it so scripts can call them like any other function.
+type Registry struct {
+	names   []string
+	natives map[string]Callable
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		natives: map[string]Callable{},
+	}
+}
+
+// Builtins returns a registry holding the natives every script can call.
+// print_all writes to out.
+func Builtins(out io.Writer) *Registry {
+	r := NewRegistry()
+	r.MustRegister("clock", NewClock())
+	r.MustRegister("range", NewRange())
+	r.MustRegister("len", NewLen())
+	r.MustRegister("print_all", NewPrintAll(out))
+	return r
+}
+
+// Register makes fn callable from scripts as name, replacing any native of
+// that name. fn is either a Callable, used as it is, or a Go function,
+// wrapped with NewNative.
+func (r *Registry) Register(name string, fn interface{}) error {
+	if token.LookupIdentifier(name) != token.IDENTIFIER || !isIdentifier(name) {
+		return fmt.Errorf("native name %q is not an identifier", name)
+	}
+	c, ok := fn.(Callable)
+	if !ok {
+		native, err := NewNative(name, fn)
+		if err != nil {
+			return err
+		}
+		c = native
+	}
+	if _, ok := r.natives[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.natives[name] = c
+	return nil
+}
+
+// MustRegister is Register for natives known to be valid; it panics on
+// error.
+func (r *Registry) MustRegister(name string, fn interface{}) {
+	if err := r.Register(name, fn); err != nil {
+		panic(err)
+	}
+}
+
+// Names lists the registered natives in the order they were first
+// registered.
+func (r *Registry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// Define defines every registered native in env.
+func (r *Registry) Define(env *environment.Environment) {
+	for _, name := range r.names {
+		env.Define(name, r.natives[name])
+	}
+}
+
+func isIdentifier(name string) bool {
+	for i, c := range name {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,449 @@
+package debug
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/resolver"
+)
+
+// threadID is the only thread a script runs on.
+const threadID = 1
+
+// mode is what the debugger does when the script is about to run a
+// statement.
+type mode int
+
+const (
+	// running stops only at breakpoints and when a pause is requested.
+	running mode = iota
+	// stepIn stops at the next statement.
+	stepIn
+	// stepOver stops at the next statement in the same frame or an outer one.
+	stepOver
+	// stepOut stops at the next statement in an outer frame.
+	stepOut
+)
+
+// Session debugs a single script for a client speaking the Debug Adapter
+// Protocol. The script runs on its own goroutine, which blocks in Before
+// while it is stopped.
+type Session struct {
+	in *bufio.Reader
+
+	// writeMu guards out and seq.
+	writeMu sync.Mutex
+	out     io.Writer
+	seq     int
+
+	program     string
+	stopOnEntry bool
+	started     bool
+
+	// mu guards the fields below, shared with the script goroutine.
+	mu          sync.Mutex
+	breakpoints map[string]map[int]bool
+	mode        mode
+	// depth is the number of frames when the current step began.
+	depth int
+	// pauseRequested stops the script at its next statement.
+	pauseRequested bool
+	// frames are those of the statement the script is stopped at, nil
+	// while it runs.
+	frames []interpreter.Frame
+	// scopes maps the variablesReference of each scope handed out while
+	// stopped to its environment.
+	scopes map[int]*environment.Environment
+	// detached lets the script run to the end without stopping.
+	detached bool
+
+	// ctx is the context the script runs under; cancel stops it.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	resume chan struct{}
+	done   chan struct{}
+}
+
+// NewSession returns a session reading requests from in and writing
+// responses and events to out. program is debugged unless the launch
+// request names another script.
+func NewSession(in io.Reader, out io.Writer, program string) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Session{
+		ctx:         ctx,
+		cancel:      cancel,
+		in:          bufio.NewReader(in),
+		out:         out,
+		program:     program,
+		breakpoints: map[string]map[int]bool{},
+		resume:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Serve handles requests until the client disconnects or closes the
+// connection. A script still running is let run to the end unless the
+// client asked for it to be terminated.
+func (s *Session) Serve() error {
+	defer func() {
+		if !s.started {
+			close(s.done)
+		}
+		s.detach()
+	}()
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+		if !s.handle(&req) {
+			return nil
+		}
+	}
+}
+
+// Wait blocks until the script has finished, or until Serve has returned
+// when it never started.
+func (s *Session) Wait() {
+	<-s.done
+}
+
+func (s *Session) read() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Session) write(msg func(seq int) interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.seq++
+	body, err := json.Marshal(msg(s.seq))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Session) respond(req *request, body interface{}) {
+	s.write(func(seq int) interface{} {
+		return response{Seq: seq, Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: body}
+	})
+}
+
+func (s *Session) fail(req *request, message string) {
+	s.write(func(seq int) interface{} {
+		return response{Seq: seq, Type: "response", RequestSeq: req.Seq, Success: false, Command: req.Command, Message: message}
+	})
+}
+
+func (s *Session) send(name string, body interface{}) {
+	s.write(func(seq int) interface{} {
+		return event{Seq: seq, Type: "event", Event: name, Body: body}
+	})
+}
+
+// handle answers a request and reports whether to keep serving.
+func (s *Session) handle(req *request) bool {
+	switch req.Command {
+	case "initialize":
+		s.respond(req, Capabilities{SupportsConfigurationDoneRequest: true, SupportsTerminateRequest: true})
+		s.send("initialized", nil)
+	case "launch":
+		var args LaunchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		if args.Program != "" {
+			s.program = args.Program
+		}
+		if s.program == "" {
+			s.fail(req, "no program to debug")
+			return true
+		}
+		s.stopOnEntry = args.StopOnEntry
+		s.respond(req, nil)
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.respond(req, s.setBreakpoints(args))
+	case "configurationDone":
+		s.respond(req, nil)
+		s.started = true
+		go s.run()
+	case "threads":
+		s.respond(req, ThreadsResponse{Threads: []Thread{{ID: threadID, Name: "main"}}})
+	case "stackTrace":
+		s.respond(req, s.stackTrace())
+	case "scopes":
+		var args ScopesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.respond(req, s.scopesOf(args.FrameID))
+	case "variables":
+		var args VariablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.respond(req, s.variables(args.VariablesReference))
+	case "continue":
+		s.respond(req, ContinueResponse{AllThreadsContinued: true})
+		s.step(running)
+	case "next":
+		s.respond(req, nil)
+		s.step(stepOver)
+	case "stepIn":
+		s.respond(req, nil)
+		s.step(stepIn)
+	case "stepOut":
+		s.respond(req, nil)
+		s.step(stepOut)
+	case "pause":
+		s.mu.Lock()
+		s.pauseRequested = true
+		s.mu.Unlock()
+		s.respond(req, nil)
+	case "terminate":
+		// The script stops at its next loop iteration, call or top-level
+		// statement, then the client is told it has terminated.
+		s.cancel()
+		s.respond(req, nil)
+		s.detach()
+	case "disconnect":
+		var args DisconnectArguments
+		if len(req.Arguments) > 0 {
+			if err := json.Unmarshal(req.Arguments, &args); err != nil {
+				s.fail(req, err.Error())
+				return true
+			}
+		}
+		if args.TerminateDebuggee {
+			s.cancel()
+		}
+		s.respond(req, nil)
+		return false
+	default:
+		s.fail(req, fmt.Sprintf("%s is not supported", req.Command))
+	}
+	return true
+}
+
+func (s *Session) setBreakpoints(args SetBreakpointsArguments) SetBreakpointsResponse {
+	lines := map[int]bool{}
+	breakpoints := []Breakpoint{}
+	for _, breakpoint := range args.Breakpoints {
+		lines[breakpoint.Line] = true
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: breakpoint.Line})
+	}
+	s.mu.Lock()
+	s.breakpoints[absolute(args.Source.Path)] = lines
+	s.mu.Unlock()
+	return SetBreakpointsResponse{Breakpoints: breakpoints}
+}
+
+// run interprets the program, then tells the client it has finished.
+func (s *Session) run() {
+	defer close(s.done)
+	exitCode := 0
+	modules := loader.NewLoader()
+	module, err := modules.Load(s.program)
+	i := interpreter.NewInterpreter()
+	if err == nil {
+		i.File = module.File
+		err = resolver.NewResolver(i, module.File).Resolve(module.Statements)
+	}
+	if err == nil {
+		i.Debugger = s
+		i.Output = outputWriter{s, "stdout"}
+		i.Diagnostics = outputWriter{s, "stderr"}
+		err = i.Interpret(s.ctx, module.Statements)
+	}
+	if err != nil {
+		s.send("output", OutputEvent{Category: "stderr", Output: modules.Diagnostic(err) + "\n"})
+		exitCode = 1
+	} else if i.Failed() {
+		exitCode = 1
+	}
+	s.send("exited", ExitedEvent{ExitCode: exitCode})
+	s.send("terminated", nil)
+}
+
+// outputWriter sends what is written to it to the client as output events.
+type outputWriter struct {
+	s        *Session
+	category string
+}
+
+func (w outputWriter) Write(b []byte) (int, error) {
+	w.s.send("output", OutputEvent{Category: w.category, Output: string(b)})
+	return len(b), nil
+}
+
+// Before stops the script, when it should, until the client resumes it.
+func (s *Session) Before(stmt ast.Statement, frames []interpreter.Frame) {
+	s.mu.Lock()
+	reason, stop := s.shouldStop(stmt, len(frames))
+	if !stop {
+		s.mu.Unlock()
+		return
+	}
+	s.pauseRequested = false
+	s.frames = frames
+	s.scopes = map[int]*environment.Environment{}
+	s.mu.Unlock()
+
+	s.send("stopped", StoppedEvent{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+	<-s.resume
+}
+
+func (s *Session) shouldStop(stmt ast.Statement, depth int) (string, bool) {
+	if s.detached {
+		return "", false
+	}
+	span := stmt.Span()
+	switch {
+	case s.pauseRequested:
+		return "pause", true
+	case s.stopOnEntry:
+		s.stopOnEntry = false
+		return "entry", true
+	case s.breakpoints[absolute(span.File)][span.Start.Line]:
+		return "breakpoint", true
+	case s.mode == stepIn,
+		s.mode == stepOver && depth <= s.depth,
+		s.mode == stepOut && depth < s.depth:
+		return "step", true
+	}
+	return "", false
+}
+
+// step resumes the stopped script in the given mode.
+func (s *Session) step(m mode) {
+	s.mu.Lock()
+	if s.frames == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.mode = m
+	s.depth = len(s.frames)
+	s.frames = nil
+	s.scopes = nil
+	s.mu.Unlock()
+	s.resume <- struct{}{}
+}
+
+// detach lets the script, if it is stopped, run to the end.
+func (s *Session) detach() {
+	s.mu.Lock()
+	s.detached = true
+	s.mu.Unlock()
+	s.step(running)
+}
+
+func (s *Session) stackTrace() StackTraceResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	frames := []StackFrame{}
+	for id, frame := range s.frames {
+		if frame.Statement == nil {
+			continue
+		}
+		span := frame.Statement.Span()
+		frames = append(frames, StackFrame{
+			ID:     id,
+			Name:   frame.Name,
+			Source: Source{Name: filepath.Base(span.File), Path: absolute(span.File)},
+			Line:   span.Start.Line,
+			Column: span.Start.Column,
+		})
+	}
+	return StackTraceResponse{StackFrames: frames, TotalFrames: len(frames)}
+}
+
+// scopesOf lists the environment chain of a frame, innermost first.
+func (s *Session) scopesOf(frameID int) ScopesResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scopes := []Scope{}
+	if frameID < 0 || frameID >= len(s.frames) {
+		return ScopesResponse{Scopes: scopes}
+	}
+	for env := s.frames[frameID].Environment; env != nil; env = env.Enclosing() {
+		name := "Locals"
+		switch {
+		case env.Enclosing() == nil:
+			name = "Globals"
+		case len(scopes) > 0:
+			name = "Enclosing"
+		}
+		reference := len(s.scopes) + 1
+		s.scopes[reference] = env
+		scopes = append(scopes, Scope{Name: name, VariablesReference: reference})
+	}
+	return ScopesResponse{Scopes: scopes}
+}
+
+func (s *Session) variables(reference int) VariablesResponse {
+	s.mu.Lock()
+	env := s.scopes[reference]
+	s.mu.Unlock()
+	variables := []Variable{}
+	if env == nil {
+		return VariablesResponse{Variables: variables}
+	}
+	values := env.Values()
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variables = append(variables, Variable{Name: name, Value: object.Inspect(values[name])})
+	}
+	return VariablesResponse{Variables: variables}
+}
+
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,286 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const script = `fn double(x):
+    return x * 2
+Scenario "debugging":
+    Given a = 21
+    And b = double(a)
+    Then b == 42
+`
+
+// client drives a session the way an editor would.
+type client struct {
+	t        *testing.T
+	in       io.Writer
+	seq      int
+	messages chan map[string]json.RawMessage
+}
+
+func newClient(t *testing.T, program string) (*client, *Session) {
+	requests, in := io.Pipe()
+	out, responses := io.Pipe()
+	session := NewSession(requests, responses, program)
+	c := &client{t: t, in: in, messages: make(chan map[string]json.RawMessage, 100)}
+	go func() {
+		reader := bufio.NewReader(out)
+		for {
+			header, err := textproto.NewReader(reader).ReadMIMEHeader()
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			length, _ := strconv.Atoi(header.Get("Content-Length"))
+			body := make([]byte, length)
+			io.ReadFull(reader, body)
+			var msg map[string]json.RawMessage
+			json.Unmarshal(body, &msg)
+			c.messages <- msg
+		}
+	}()
+	go func() {
+		session.Serve()
+		in.Close()
+	}()
+	return c, session
+}
+
+func (c *client) send(command string, arguments interface{}) {
+	c.t.Helper()
+	c.seq++
+	body, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+	fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// await returns the body of the next response to command, or of the next
+// event of that name, skipping other messages.
+func (c *client) await(kind string, name string, body interface{}) {
+	c.t.Helper()
+	key := "command"
+	if kind == "event" {
+		key = "event"
+	}
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				c.t.Fatalf("connection closed waiting for %s %s", kind, name)
+			}
+			var msgKind, msgName string
+			json.Unmarshal(msg["type"], &msgKind)
+			json.Unmarshal(msg[key], &msgName)
+			if msgKind != kind || msgName != name {
+				continue
+			}
+			if success, ok := msg["success"]; ok && string(success) != "true" {
+				c.t.Fatalf("%s failed: %s", name, msg["message"])
+			}
+			if body != nil {
+				json.Unmarshal(msg["body"], body)
+			}
+			return
+		case <-time.After(5 * time.Second):
+			c.t.Fatalf("timed out waiting for %s %s", kind, name)
+		}
+	}
+}
+
+// stoppedAt waits for the script to stop and checks the frames it is in.
+func (c *client) stoppedAt(reason string, names []string, line int) {
+	c.t.Helper()
+	var stopped StoppedEvent
+	c.await("event", "stopped", &stopped)
+	if stopped.Reason != reason {
+		c.t.Fatalf("stop reason wrong. expected=%q, got=%q", reason, stopped.Reason)
+	}
+	c.send("stackTrace", map[string]int{"threadId": threadID})
+	var trace StackTraceResponse
+	c.await("response", "stackTrace", &trace)
+	if len(trace.StackFrames) != len(names) {
+		c.t.Fatalf("frames wrong. expected=%v, got=%+v", names, trace.StackFrames)
+	}
+	for i, name := range names {
+		if trace.StackFrames[i].Name != name {
+			c.t.Fatalf("frames[%d] wrong. expected=%q, got=%q", i, name, trace.StackFrames[i].Name)
+		}
+	}
+	if trace.StackFrames[0].Line != line {
+		c.t.Fatalf("line wrong. expected=%d, got=%d", line, trace.StackFrames[0].Line)
+	}
+}
+
+// locals checks the innermost scope of the top frame.
+func (c *client) locals(expected map[string]string) {
+	c.t.Helper()
+	c.send("scopes", ScopesArguments{FrameID: 0})
+	var scopes ScopesResponse
+	c.await("response", "scopes", &scopes)
+	if len(scopes.Scopes) < 2 || scopes.Scopes[0].Name != "Locals" || scopes.Scopes[len(scopes.Scopes)-1].Name != "Globals" {
+		c.t.Fatalf("scopes wrong. got=%+v", scopes.Scopes)
+	}
+	c.send("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference})
+	var variables VariablesResponse
+	c.await("response", "variables", &variables)
+	if len(variables.Variables) != len(expected) {
+		c.t.Fatalf("variables wrong. expected=%v, got=%+v", expected, variables.Variables)
+	}
+	for _, variable := range variables.Variables {
+		if expected[variable.Name] != variable.Value {
+			c.t.Fatalf("variable %s wrong. expected=%q, got=%q", variable.Name, expected[variable.Name], variable.Value)
+		}
+	}
+}
+
+func writeScript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "debug.ac")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBreakpointsAndStepping(t *testing.T) {
+	program := writeScript(t)
+	c, session := newClient(t, "")
+
+	c.send("initialize", map[string]string{"adapterID": "ofin"})
+	c.await("event", "initialized", nil)
+	c.send("launch", LaunchArguments{Program: program})
+	c.await("response", "launch", nil)
+	c.send("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: program}, Breakpoints: []SourceBreakpoint{{Line: 5}}})
+	var breakpoints SetBreakpointsResponse
+	c.await("response", "setBreakpoints", &breakpoints)
+	if len(breakpoints.Breakpoints) != 1 || !breakpoints.Breakpoints[0].Verified {
+		t.Fatalf("breakpoints wrong. got=%+v", breakpoints)
+	}
+	c.send("configurationDone", nil)
+
+	c.stoppedAt("breakpoint", []string{`Scenario "debugging"`}, 5)
+	c.locals(map[string]string{"a": "21"})
+
+	c.send("stepIn", map[string]int{"threadId": threadID})
+	c.stoppedAt("step", []string{"double", `Scenario "debugging"`}, 2)
+	c.locals(map[string]string{"x": "21"})
+
+	c.send("stepOut", map[string]int{"threadId": threadID})
+	c.stoppedAt("step", []string{`Scenario "debugging"`}, 6)
+	c.locals(map[string]string{"a": "21", "b": "42"})
+
+	c.send("next", map[string]int{"threadId": threadID})
+	var exited ExitedEvent
+	c.await("event", "exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Fatalf("exit code wrong. expected=0, got=%d", exited.ExitCode)
+	}
+	c.await("event", "terminated", nil)
+	c.send("disconnect", nil)
+	session.Wait()
+}
+
+func TestStopOnEntryAndStepOver(t *testing.T) {
+	c, session := newClient(t, writeScript(t))
+
+	c.send("initialize", nil)
+	c.send("launch", LaunchArguments{StopOnEntry: true})
+	c.await("response", "launch", nil)
+	c.send("configurationDone", nil)
+
+	c.stoppedAt("entry", []string{"<script>"}, 1)
+	// The scenario starts once its Scenario line has run.
+	c.send("next", map[string]int{"threadId": threadID})
+	c.stoppedAt("step", []string{"<script>"}, 3)
+	for _, line := range []int{4, 5, 6} {
+		c.send("next", map[string]int{"threadId": threadID})
+		c.stoppedAt("step", []string{`Scenario "debugging"`}, line)
+	}
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.await("event", "terminated", nil)
+	c.send("disconnect", nil)
+	session.Wait()
+}
+
+func TestBreakpointInDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defaults.ac")
+	source := `fn g():
+    return 1
+fn f(a = g()):
+    return a
+Scenario "defaults":
+    Given b = f()
+`
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, session := newClient(t, path)
+
+	c.send("initialize", nil)
+	c.send("launch", LaunchArguments{})
+	c.await("response", "launch", nil)
+	c.send("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: path}, Breakpoints: []SourceBreakpoint{{Line: 2}}})
+	c.await("response", "setBreakpoints", nil)
+	c.send("configurationDone", nil)
+
+	// f is still evaluating its defaults, so its frame is at its declaration.
+	c.stoppedAt("breakpoint", []string{"g", "f", `Scenario "defaults"`}, 2)
+	c.send("continue", map[string]int{"threadId": threadID})
+	c.await("event", "terminated", nil)
+	c.send("disconnect", nil)
+	session.Wait()
+}
+
+const spin = `Scenario "spins":
+    Given i = 0
+    while true:
+        i = i + 1
+`
+
+func TestTerminate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spin.ac")
+	if err := os.WriteFile(path, []byte(spin), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, session := newClient(t, path)
+
+	c.send("initialize", nil)
+	c.send("launch", LaunchArguments{})
+	c.await("response", "launch", nil)
+	c.send("configurationDone", nil)
+	c.await("response", "configurationDone", nil)
+	c.send("terminate", nil)
+	c.await("response", "terminate", nil)
+	c.await("event", "terminated", nil)
+	c.send("disconnect", nil)
+	session.Wait()
+}
+
+func TestDisconnectTerminatesDebuggee(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spin.ac")
+	if err := os.WriteFile(path, []byte(spin), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, session := newClient(t, path)
+
+	c.send("initialize", nil)
+	c.send("launch", LaunchArguments{})
+	c.await("response", "launch", nil)
+	c.send("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: path}, Breakpoints: []SourceBreakpoint{{Line: 4}}})
+	c.await("response", "setBreakpoints", nil)
+	c.send("configurationDone", nil)
+	c.stoppedAt("breakpoint", []string{`Scenario "spins"`}, 4)
+	c.send("disconnect", DisconnectArguments{TerminateDebuggee: true})
+	c.await("response", "disconnect", nil)
+	session.Wait()
+}
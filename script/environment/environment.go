@@ -37,7 +37,7 @@ func (e *Environment) Assign(name token.Token, value interface{}) {
 		e.enclosing.Assign(name, value)
 		return
 	}
-	merror.RuntimeError(name, "Undefined variable '"+name.Lexeme+"'.")
+	merror.Runtime(name, "Undefined variable '"+name.Lexeme+"'.")
 
 }
 func (e *Environment) Get(name token.Token) (interface{}, error) {
@@ -50,3 +50,37 @@ func (e *Environment) Get(name token.Token) (interface{}, error) {
 	}
 	return nil, fmt.Errorf("variable %s is undefined", name.Lexeme)
 }
+
+// Values is a copy of the variables defined in this scope, leaving out the
+// enclosing ones.
+func (e *Environment) Values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for name, value := range e.value {
+		values[name] = value
+	}
+	return values
+}
+
+// Enclosing is the scope this one is nested in, nil for the global scope.
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
+}
+
+func (e *Environment) GetAt(distance int, name token.Token) (interface{}, error) {
+	if v, ok := e.ancestor(distance).value[name.Lexeme]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("variable %s is undefined", name.Lexeme)
+}
+
+func (e *Environment) AssignAt(distance int, name token.Token, value interface{}) {
+	e.ancestor(distance).value[name.Lexeme] = value
+}
+
+func (e *Environment) ancestor(distance int) *Environment {
+	environment := e
+	for i := 0; i < distance; i++ {
+		environment = environment.enclosing
+	}
+	return environment
+}
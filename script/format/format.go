@@ -0,0 +1,196 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/parser"
+	"github.com/itsert/ofin/script/token"
+)
+
+// DefaultIndent is the indentation width used when none is configured.
+const DefaultIndent = 4
+
+// stepKeywords maps the step keywords, lowercased, to their canonical
+// spelling.
+var stepKeywords = map[string]string{
+	"given": "Given",
+	"when":  "When",
+	"then":  "Then",
+	"and":   "And",
+}
+
+// line is a source line holding code.
+type line struct {
+	// depth is the number of blocks the line is nested in.
+	depth int
+	// column is where the line started in the original script.
+	column int
+	tokens []token.Token
+}
+
+// Source formats a script: each line is indented by indent spaces per block,
+// tokens are spaced canonically and step keywords are capitalised. Comments
+// and blank lines are kept, so every line stays on the same line number.
+//
+// When err is not nil, the returned text is the one the errors point into:
+// the script itself when it cannot be scanned, the formatted script when it
+// cannot be parsed.
+func Source(source string, fileName string, indent int) (string, error) {
+	if indent <= 0 {
+		indent = DefaultIndent
+	}
+	l := lexer.NewLexer(source, fileName)
+	tokens, err := l.Tokenize()
+	if err != nil {
+		return source, err
+	}
+
+	lines := map[int]*line{}
+	last := 0
+	depth := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case token.INDENT:
+			depth++
+			continue
+		case token.DEDENT:
+			depth--
+			continue
+		case token.NEWLINE, token.EOF:
+			continue
+		}
+		code, ok := lines[tok.Line]
+		if !ok {
+			code = &line{depth: depth, column: tok.Column}
+			lines[tok.Line] = code
+		}
+		code.tokens = append(code.tokens, tok)
+		if tok.Line > last {
+			last = tok.Line
+		}
+	}
+	comments := map[int]token.Token{}
+	for _, comment := range l.Comments() {
+		comments[comment.Line] = comment
+		if comment.Line > last {
+			last = comment.Line
+		}
+	}
+
+	var out strings.Builder
+	for n := 1; n <= last; n++ {
+		code, hasCode := lines[n]
+		comment, hasComment := comments[n]
+		switch {
+		case hasCode:
+			out.WriteString(strings.Repeat(" ", code.depth*indent))
+			out.WriteString(join(code.tokens))
+			if hasComment {
+				out.WriteString(" " + strings.TrimRight(comment.Lexeme, " \t\r"))
+			}
+		case hasComment:
+			out.WriteString(strings.Repeat(" ", commentDepth(lines, n, last, comment)*indent))
+			out.WriteString(strings.TrimRight(comment.Lexeme, " \t\r"))
+		}
+		out.WriteString("\n")
+	}
+
+	formatted := out.String()
+	if _, err := parser.NewParser(lexer.NewLexer(formatted, fileName)).ParseProgram(); err != nil {
+		return formatted, err
+	}
+	return formatted, nil
+}
+
+// commentDepth is the depth of a comment on a line of its own. It lines up
+// with the code after it, unless it was indented further than that code and
+// the code before it is deeper, as a comment closing a block is.
+func commentDepth(lines map[int]*line, n int, last int, comment token.Token) int {
+	var previous, next *line
+	for i := n - 1; i > 0 && previous == nil; i-- {
+		previous = lines[i]
+	}
+	for i := n + 1; i <= last && next == nil; i++ {
+		next = lines[i]
+	}
+	switch {
+	case next == nil && previous == nil:
+		return 0
+	case next == nil:
+		return previous.depth
+	case previous != nil && comment.Column > next.column && previous.depth > next.depth:
+		return previous.depth
+	}
+	return next.depth
+}
+
+// join lays out the tokens of a line with canonical spacing.
+func join(tokens []token.Token) string {
+	var b strings.Builder
+	for i, tok := range tokens {
+		if i == 0 {
+			b.WriteString(stepKeyword(tokens))
+			continue
+		}
+		if spaced(tokens, i) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tok.Lexeme)
+	}
+	return b.String()
+}
+
+// stepKeyword is the first token of a line, capitalised if it is a step
+// keyword in any case. A lowercase "given" or "when" followed by an
+// assignment, a call, an index or a property is a variable and left alone.
+func stepKeyword(tokens []token.Token) string {
+	first := tokens[0]
+	keyword, ok := stepKeywords[strings.ToLower(first.Lexeme)]
+	if !ok {
+		return first.Lexeme
+	}
+	if first.Type == token.IDENTIFIER && len(tokens) > 1 {
+		switch tokens[1].Type {
+		case token.ASSIGN, token.DOT, token.LEFT_PAREN, token.LEFT_BRACKET:
+			return first.Lexeme
+		}
+	}
+	return keyword
+}
+
+// spaced reports whether a space separates tokens[i] from the token before
+// it.
+func spaced(tokens []token.Token, i int) bool {
+	previous, tok := tokens[i-1], tokens[i]
+	switch previous.Type {
+	case token.LEFT_PAREN, token.LEFT_BRACKET, token.LEFT_BRACE, token.DOT:
+		return false
+	case token.BANG:
+		return false
+	case token.MINUS:
+		if i == 1 || !operand(tokens[i-2]) {
+			return false
+		}
+	}
+	switch tok.Type {
+	case token.RIGHT_PAREN, token.RIGHT_BRACKET, token.RIGHT_BRACE,
+		token.COMMA, token.COLON, token.SEMICOLON, token.DOT:
+		return false
+	case token.LEFT_PAREN, token.LEFT_BRACKET:
+		// A call or an index.
+		return !operand(previous)
+	}
+	return true
+}
+
+// operand reports whether tok ends an operand, after which a minus sign is
+// a subtraction and a parenthesis a call.
+func operand(tok token.Token) bool {
+	switch tok.Type {
+	case token.IDENTIFIER, token.NUMBER, token.STRING, token.TRUE, token.FALSE, token.NIL,
+		token.RIGHT_PAREN, token.RIGHT_BRACKET, token.RIGHT_BRACE:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/itsert/ofin/merror"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		indent   int
+		expected string
+	}{
+		{
+			"Given a=1+2*-3\n",
+			4,
+			"Given a = 1 + 2 * -3\n",
+		},
+		{
+			"given  a = [ 1,2 ]\nand b = { \"x\" :a[ 0 ] }\nthen a[0]==1 and !false\n",
+			4,
+			"Given a = [1, 2]\nAnd b = {\"x\": a[0]}\nThen a[0] == 1 and !false\n",
+		},
+		{
+			"fn add( a,b ):\n  return a-b\nprint add (1, - 2)\n",
+			4,
+			"fn add(a, b):\n    return a - b\nprint add(1, -2)\n",
+		},
+		{
+			"Scenario \"s\" :\n    Given a = 1\n    when:\n        print a\n    Then a == 1\n",
+			2,
+			"Scenario \"s\":\n  Given a = 1\n  When:\n    print a\n  Then a == 1\n",
+		},
+		{
+			"// leading\n\nScenario \"s\":   // trailing   \n  Given a = 1\n  // inside\n\n    // closing\n// outside\nprint a\n\n\n",
+			4,
+			"// leading\n\nScenario \"s\": // trailing\n    Given a = 1\n    // inside\n\n    // closing\n// outside\nprint a\n",
+		},
+		{
+			"given = 1\nthen(2)\n",
+			4,
+			"given = 1\nthen(2)\n",
+		},
+	}
+
+	for i, tt := range tests {
+		formatted, err := Source(tt.input, "test.ac", tt.indent)
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error %v", i, err)
+		}
+		if formatted != tt.expected {
+			t.Fatalf("tests[%d] - formatted wrong. expected=%q, got=%q", i, tt.expected, formatted)
+		}
+		again, _ := Source(formatted, "test.ac", tt.indent)
+		if again != formatted {
+			t.Fatalf("tests[%d] - formatting not stable. expected=%q, got=%q", i, formatted, again)
+		}
+	}
+}
+
+func TestSourceWithErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{"Given a = 1\nGiven b = @\n", 2},
+		{"Given a = 1\nThen a ==\n", 2},
+	}
+
+	for i, tt := range tests {
+		_, err := Source(tt.input, "test.ac", 4)
+		errors, ok := err.(merror.ErrorList)
+		if !ok || len(errors) == 0 {
+			t.Fatalf("tests[%d] - expected an ErrorList, got=%#v", i, err)
+		}
+		if errors[0].Line != tt.line {
+			t.Fatalf("tests[%d] - line wrong. expected=%d, got=%d", i, tt.line, errors[0].Line)
+		}
+	}
+}
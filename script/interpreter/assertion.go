@@ -0,0 +1,12 @@
+package interpreter
+
+// AssertionPolicy decides what happens to the rest of a scenario once one
+// of its Then assertions fails.
+type AssertionPolicy int
+
+const (
+	// StopScenario skips the remaining steps of the failing scenario.
+	StopScenario AssertionPolicy = iota
+	// ContinueScenario records the failure and keeps running the scenario.
+	ContinueScenario
+)
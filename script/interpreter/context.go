@@ -0,0 +1,47 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/token"
+)
+
+// startScenarioContext gives the scenario starting its own deadline when
+// ScenarioTimeout is set.
+func (p *Interpreter) startScenarioContext() {
+	p.stopScenarioContext()
+	if p.ScenarioTimeout > 0 {
+		p.scenarioCtx, p.cancelScenario = context.WithTimeout(p.ctx, p.ScenarioTimeout)
+	}
+}
+
+func (p *Interpreter) stopScenarioContext() {
+	if p.cancelScenario != nil {
+		p.cancelScenario()
+	}
+	p.scenarioCtx, p.cancelScenario = nil, nil
+}
+
+// checkRun raises a runtime error at span once the run has been canceled
+// or has run out of time.
+func (p *Interpreter) checkRun(span token.Span) {
+	switch p.ctx.Err() {
+	case nil:
+	case context.DeadlineExceeded:
+		merror.RuntimeAt(span, "run timed out")
+	default:
+		merror.RuntimeAt(span, "run canceled")
+	}
+}
+
+// checkContext is checkRun that also raises once the current scenario has
+// run out of time. Loops and calls check it so a script that never
+// finishes can be stopped.
+func (p *Interpreter) checkContext(span token.Span) {
+	p.checkRun(span)
+	if p.scenarioCtx != nil && p.scenarioCtx.Err() != nil {
+		merror.RuntimeAt(span, fmt.Sprintf("scenario timed out after %v", p.ScenarioTimeout))
+	}
+}
@@ -0,0 +1,61 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/environment"
+)
+
+// Debugger is told about each statement before it is executed. It may block
+// to pause the script, for as long as it needs to inspect the frames.
+type Debugger interface {
+	Before(stmt ast.Statement, frames []Frame)
+}
+
+// Frame is an entry of the call stack: the script itself, at the bottom, or
+// a function call.
+type Frame struct {
+	Name string
+	// Statement is the statement the frame is executing.
+	Statement   ast.Statement
+	Environment *environment.Environment
+}
+
+// Frames is the call stack, innermost frame first.
+func (p *Interpreter) Frames() []Frame {
+	frames := make([]Frame, len(p.frames))
+	for i, frame := range p.frames {
+		frames[len(p.frames)-1-i] = *frame
+	}
+	if p.scenario != nil {
+		frames[len(frames)-1].Name = fmt.Sprintf("Scenario %q", p.scenario.Name)
+	}
+	return frames
+}
+
+// pause hands stmt to the debugger, if there is one. Blocks are skipped as
+// the debugger is told about each of their statements.
+func (p *Interpreter) pause(stmt ast.Statement) {
+	if p.Debugger == nil {
+		return
+	}
+	switch stmt.(type) {
+	case *ast.Block, *ast.DoNoting:
+		return
+	}
+	frame := p.frames[len(p.frames)-1]
+	frame.Statement = stmt
+	frame.Environment = p.environment
+	p.Debugger.Before(stmt, p.Frames())
+}
+
+// pushFrame starts a frame at stmt, which it executes until pause moves it
+// on to the first statement of its body.
+func (p *Interpreter) pushFrame(name string, stmt ast.Statement, env *environment.Environment) {
+	p.frames = append(p.frames, &Frame{Name: name, Statement: stmt, Environment: env})
+}
+
+func (p *Interpreter) popFrame() {
+	p.frames = p.frames[:len(p.frames)-1]
+}
@@ -0,0 +1,77 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/callable"
+	"github.com/itsert/ofin/script/environment"
+)
+
+// Function is the runtime value of an `fn` declaration.
+type Function struct {
+	declaration *ast.Function
+	closure     *environment.Environment
+	interpreter *Interpreter
+}
+
+// returnValue unwinds the interpreter from a `return` statement back to the
+// Call that is executing the function body.
+type returnValue struct {
+	value interface{}
+}
+
+func NewFunction(declaration *ast.Function, closure *environment.Environment, interpreter *Interpreter) *Function {
+	return &Function{
+		declaration: declaration,
+		closure:     closure,
+		interpreter: interpreter,
+	}
+}
+
+// Arity counts the parameters up to the first with a default as required.
+func (f *Function) Arity() callable.Arity {
+	required := 0
+	for required < len(f.declaration.Defaults) && f.declaration.Defaults[required] == nil {
+		required += 1
+	}
+	return callable.Arity{Min: required, Max: len(f.declaration.Params)}
+}
+
+func (f *Function) Call(env *environment.Environment, arguments []interface{}) (result interface{}) {
+	// The body runs against the environment the function was declared in,
+	// not the one it is called from.
+	functionEnv := environment.NewEnvironmentWithParent(f.closure)
+
+	f.interpreter.functionDepth += 1
+	f.interpreter.pushFrame(f.declaration.Name.Lexeme, f.declaration, functionEnv)
+	previous := f.interpreter.environment
+	defer func() {
+		f.interpreter.functionDepth -= 1
+		f.interpreter.popFrame()
+		f.interpreter.environment = previous
+		if r := recover(); r != nil {
+			if ret, ok := r.(*returnValue); ok {
+				result = ret.value
+				return
+			}
+			panic(r)
+		}
+	}()
+	// Defaults are evaluated on each call, so they can use the parameters
+	// before them and a list or map default is not shared between calls.
+	f.interpreter.environment = functionEnv
+	for i, param := range f.declaration.Params {
+		if i < len(arguments) {
+			functionEnv.Define(param.Lexeme, arguments[i])
+		} else {
+			functionEnv.Define(param.Lexeme, f.interpreter.evaluate(f.declaration.Defaults[i]))
+		}
+	}
+	f.interpreter.executeBlock(f.declaration.Body, functionEnv)
+	return nil
+}
+
+func (f *Function) String() string {
+	return fmt.Sprintf("<fn %s>", f.declaration.Name.Lexeme)
+}
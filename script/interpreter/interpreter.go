@@ -1,34 +1,79 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/itsert/ofin/script/callable"
 
 	"github.com/itsert/ofin/merror"
 	"github.com/itsert/ofin/script/ast"
 	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/result"
 	"github.com/itsert/ofin/script/token"
 )
 
 type Interpreter struct {
-	environment  *environment.Environment
-	Global       *environment.Environment
-	programState *environment.ProgramState
-	label        string
+	// File is the script being interpreted; it is recorded on runtime errors.
+	File          string
+	environment   *environment.Environment
+	Global        *environment.Environment
+	programState  *environment.ProgramState
+	label         string
+	functionDepth int
+	locals        map[ast.Expression]int
+	imported      map[string]bool // modules whose declarations have been run
+	failures      []*result.AssertionFailure
+	Policy        AssertionPolicy
+	results       *result.Run
+	scenario      *result.Scenario
+	scenarioStart time.Time
+	step          *result.Step
+	// stopped is set once the current scenario has failed or errored; its
+	// remaining steps are recorded as skipped until the next Scenario.
+	stopped bool
+	// Debugger, when set, is told about every statement before it runs.
+	Debugger Debugger
+	frames   []*Frame
+	// Output receives what the script prints, os.Stdout by default.
+	Output io.Writer
+	// Diagnostics receives messages about the interpreter itself rather
+	// than the script, os.Stderr by default.
+	Diagnostics io.Writer
+	// Capture records what each scenario prints in its result instead of
+	// writing it to Output. Output outside any scenario is written as is.
+	Capture  bool
+	captured strings.Builder
+	// ScenarioTimeout, when set, errors a scenario still running after it.
+	ScenarioTimeout time.Duration
+	// ctx is the context of the current Interpret and scenarioCtx that of
+	// the current scenario, when it has a timeout.
+	ctx            context.Context
+	scenarioCtx    context.Context
+	cancelScenario context.CancelFunc
 }
 
 func NewInterpreter() *Interpreter {
 	globals := environment.NewEnvironment()
-	defineNativeFunctions(globals)
-	return &Interpreter{
+	p := &Interpreter{
 		environment:  globals,
 		Global:       globals,
 		programState: environment.NewState(),
+		locals:       map[ast.Expression]int{},
+		imported:     map[string]bool{},
+		results:      result.NewRun(),
+		frames:       []*Frame{{Name: "<script>", Environment: globals}},
+		Output:       os.Stdout,
+		Diagnostics:  os.Stderr,
+		ctx:          context.Background(),
 	}
-}
-
-func defineNativeFunctions(env *environment.Environment) {
-	env.Define("clock", callable.NewClock())
+	callable.Builtins(output{p}).Define(globals)
+	return p
 }
 
 func (p *Interpreter) VisitCallExpression(expression *ast.Call) interface{} {
@@ -39,34 +84,159 @@ func (p *Interpreter) VisitCallExpression(expression *ast.Call) interface{} {
 	}
 	switch fn := callee.(type) {
 	case callable.Callable:
+		p.checkContext(expression.Span())
 		argList := len(arguments)
-		if argList != fn.Arity() {
-			merror.RuntimeError(
+		if !fn.Arity().Accepts(argList) {
+			merror.Runtime(
 				expression.Paren,
-				fmt.Sprintf("Expected %d arguments, but got %d. ",
+				fmt.Sprintf("Expected %s arguments, but got %d. ",
 					fn.Arity(),
 					argList))
 		}
-		return fn.Call(p.Global, arguments)
+		return p.call(expression.Paren, fn, arguments)
 	default:
-		merror.RuntimeError(expression.Paren, "Can only call functions.")
+		merror.Runtime(expression.Paren, "Can only call functions.")
 	}
 	return nil
 }
 
-func (p *Interpreter) Interpret(stmts []ast.Statement) {
+// call turns a NativeError raised by the callee into a runtime error at
+// the call site.
+func (p *Interpreter) call(paren token.Token, fn callable.Callable, arguments []interface{}) interface{} {
 	defer func() {
 		if r := recover(); r != nil {
-			// err = errors.New("error  encountered")
-			fmt.Println("Recovered in f", r)
+			if err, ok := r.(*callable.NativeError); ok {
+				merror.Runtime(paren, err.Message)
+			}
+			panic(r)
+		}
+	}()
+	return fn.Call(p.Global, arguments)
+}
+
+// Interpret executes stmts. Runtime errors inside a scenario are recorded in
+// its results; one raised outside any scenario stops the script and is
+// returned as a *merror.RuntimeError.
+//
+// Once ctx is done, the next loop iteration, call or top-level statement
+// raises a runtime error. The scenario running, if any, is errored at that
+// line, and the script stops with the error.
+func (p *Interpreter) Interpret(ctx context.Context, stmts []ast.Statement) error {
+	defer p.Finish()
+	return p.Continue(ctx, stmts)
+}
+
+// Continue is Interpret for a script given a part at a time, as at the
+// REPL: the scenario running at the end of stmts is left open, so the
+// steps of the next call are recorded in it. It is finished by the next
+// Scenario or by Finish.
+func (p *Interpreter) Continue(ctx context.Context, stmts []ast.Statement) (err error) {
+	p.ctx = ctx
+	start := time.Now()
+	defer func() {
+		p.results.Duration += time.Since(start)
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(*merror.RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = p.located(runtimeError)
 		}
 	}()
 	for _, stmt := range stmts {
-		p.execute(stmt)
+		p.executeTopLevel(stmt)
 	}
+	return nil
+}
+
+// Finish records the outcome of the scenario left open by Continue, if any.
+func (p *Interpreter) Finish() {
+	p.finishScenario()
+}
+
+// located fills in the file of a runtime error, which the token it was
+// raised at does not know.
+func (p *Interpreter) located(err *merror.RuntimeError) *merror.RuntimeError {
+	if err.File == "" {
+		err.File = p.File
+	}
+	return err
+}
+
+// errorMessage formats a value recovered from a failing step or scenario.
+func (p *Interpreter) errorMessage(r interface{}, line int) string {
+	if err, ok := r.(*merror.RuntimeError); ok {
+		return p.located(err).Error()
+	}
+	return fmt.Sprintf("[line %d] %v", line, r)
+}
+
+// executeTopLevel runs a top-level statement. A runtime error raised inside
+// a scenario errors that scenario instead of aborting the whole run.
+func (p *Interpreter) executeTopLevel(stmt ast.Statement) {
+	defer func() {
+		if r := recover(); r != nil {
+			// A step cut short by ctx has already recorded the error.
+			if p.scenario == nil || p.stopped && p.ctx.Err() != nil {
+				panic(r)
+			}
+			p.scenario.Status = result.ERRORED
+			p.scenario.Error = p.errorMessage(r, p.scenario.Line)
+			p.stopped = true
+			if p.ctx.Err() != nil {
+				panic(r)
+			}
+		}
+	}()
+	p.checkRun(stmt.Span())
+	p.execute(stmt)
+}
+
+// Evaluate evaluates a single expression against the global environment.
+func (p *Interpreter) Evaluate(expr ast.Expression) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(*merror.RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = p.located(runtimeError)
+		}
+	}()
+	return p.evaluate(expr), nil
+}
+
+// State is the step of a scenario the interpreter is in.
+func (p *Interpreter) State() environment.State {
+	return p.programState.CurrentState()
+}
+
+// Failures lists every assertion that has failed so far.
+func (p *Interpreter) Failures() []*result.AssertionFailure {
+	return p.failures
+}
+
+// Results is the outcome of every scenario interpreted so far.
+func (p *Interpreter) Results() *result.Run {
+	return p.results
+}
+
+func (p *Interpreter) Failed() bool {
+	return len(p.failures) > 0 || p.results.Failed()
 }
 
 func (p *Interpreter) execute(stmt ast.Statement) {
+	skip := p.stopped && !alwaysRuns(stmt)
+	if !skip {
+		p.pause(stmt)
+	}
+	if keyword, text, ok := p.stepOf(stmt); ok && p.atStepLevel() {
+		p.runStep(stmt, keyword, text)
+		return
+	}
+	if skip {
+		return
+	}
 	stmt.Accept(p)
 }
 
@@ -88,6 +258,16 @@ func (p *Interpreter) VisitLogicalExpression(expression *ast.Logical) interface{
 func (p *Interpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
 	right := p.evaluate(expr.Right)
 	left := p.evaluate(expr.Left)
+	return p.binary(expr.Operator, left, right)
+}
+
+func (p *Interpreter) binary(operator token.Token, left interface{}, right interface{}) interface{} {
+	switch operator.Type {
+	case token.BANG_EQUAL:
+		return !p.isEqual(left, right)
+	case token.EQUAL:
+		return p.isEqual(left, right)
+	}
 	_, ok1 := left.(float64)
 
 	var leftDouble, rightDouble float64
@@ -98,7 +278,7 @@ func (p *Interpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
 	case string:
 		rightString = string(i)
 	default:
-		merror.RuntimeError(expr.Operator, "Operands must be two numbers or two strings.")
+		merror.Runtime(operator, "Operands must be two numbers or two strings.")
 	}
 
 	switch i := left.(type) {
@@ -107,11 +287,11 @@ func (p *Interpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
 	case string:
 		leftString = string(i)
 	default:
-		merror.RuntimeError(expr.Operator, "Operands must be two numbers or two strings.")
+		merror.Runtime(operator, "Operands must be two numbers or two strings.")
 	}
 	_, ok2 := right.(float64)
 	if ok1 && ok2 {
-		switch expr.Operator.Type {
+		switch operator.Type {
 		case token.MINUS:
 			return float64(leftDouble) - float64(rightDouble)
 		case token.SLASH:
@@ -128,10 +308,6 @@ func (p *Interpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
 			return float64(leftDouble) < float64(rightDouble)
 		case token.LESS_EQUAL:
 			return float64(leftDouble) <= float64(rightDouble)
-		case token.BANG_EQUAL:
-			return !p.isEqual(left, right)
-		case token.EQUAL:
-			return p.isEqual(left, right)
 		}
 	}
 
@@ -139,7 +315,7 @@ func (p *Interpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
 	_, ok2 = right.(string)
 
 	if ok1 && ok2 {
-		switch expr.Operator.Type {
+		switch operator.Type {
 		case token.PLUS:
 			return string(leftString) + string(rightString)
 		}
@@ -161,7 +337,7 @@ func (p *Interpreter) VisitUnaryExpression(expr *ast.Unary) interface{} {
 		case float64:
 			return -i
 		default:
-			merror.RuntimeError(expr.Operator, "Operand must be a numbers")
+			merror.Runtime(expr.Operator, "Operand must be a numbers")
 		}
 	case token.BANG:
 		return p.expressBoolean(right)
@@ -182,16 +358,141 @@ func (p *Interpreter) expressBoolean(expr interface{}) bool {
 }
 
 func (p *Interpreter) VisitVariableExpression(expression *ast.Variable) interface{} {
-	v, err := p.environment.Get(expression.Name)
+	return p.lookUpVariable(expression.Name, expression)
+}
+
+func (p *Interpreter) lookUpVariable(name token.Token, expression ast.Expression) interface{} {
+	var v interface{}
+	var err error
+	if distance, ok := p.locals[expression]; ok {
+		v, err = p.environment.GetAt(distance, name)
+	} else {
+		v, err = p.Global.Get(name)
+	}
 	if err != nil {
-		merror.RuntimeError(expression.Name, err.Error())
+		merror.Runtime(name, err.Error())
 	}
 	return v
 }
 
 func (p *Interpreter) VisitAssignExpression(expression *ast.Assign) interface{} {
 	value := p.evaluate(expression.Expr)
-	p.environment.Assign(expression.Name, value)
+	if distance, ok := p.locals[expression]; ok {
+		p.environment.AssignAt(distance, expression.Name, value)
+	} else {
+		p.Global.Assign(expression.Name, value)
+	}
+	return value
+}
+
+// Resolve records how many environments away from the current one the
+// variable referenced by expression was declared.
+func (p *Interpreter) Resolve(expression ast.Expression, depth int) {
+	p.locals[expression] = depth
+}
+
+func (p *Interpreter) VisitListExpression(expression *ast.List) interface{} {
+	elements := []interface{}{}
+	for _, element := range expression.Elements {
+		elements = append(elements, p.evaluate(element))
+	}
+	return object.NewList(elements)
+}
+
+func (p *Interpreter) VisitMapExpression(expression *ast.Map) interface{} {
+	m := object.NewMap()
+	for i := range expression.Keys {
+		key := p.evaluate(expression.Keys[i])
+		if !object.IsHashable(key) {
+			merror.Runtime(expression.Brace, "Map keys must be strings, numbers, booleans or nil.")
+		}
+		m.Set(key, p.evaluate(expression.Values[i]))
+	}
+	return m
+}
+
+func (p *Interpreter) VisitIndexExpression(expression *ast.Index) interface{} {
+	value := p.evaluate(expression.Object)
+	key := p.evaluate(expression.Key)
+	return p.index(expression.Bracket, value, key)
+}
+
+func (p *Interpreter) index(bracket token.Token, value interface{}, key interface{}) interface{} {
+	switch v := value.(type) {
+	case *object.List:
+		return v.Elements[p.listIndex(bracket, key, len(v.Elements))]
+	case string:
+		runes := []rune(v)
+		return string(runes[p.listIndex(bracket, key, len(runes))])
+	case *object.Map:
+		if !object.IsHashable(key) {
+			merror.Runtime(bracket, "Map keys must be strings, numbers, booleans or nil.")
+		}
+		item, ok := v.Get(key)
+		if !ok {
+			merror.Runtime(bracket, fmt.Sprintf("Key %s not found.", object.Inspect(key)))
+		}
+		return item
+	default:
+		merror.Runtime(bracket, "Only lists, maps and strings can be indexed.")
+	}
+	return nil
+}
+
+// listIndex checks that key is a whole number inside [0, length) and returns it.
+func (p *Interpreter) listIndex(bracket token.Token, key interface{}, length int) int {
+	n, ok := key.(float64)
+	if !ok || n != float64(int(n)) {
+		merror.Runtime(bracket, "Index must be a whole number.")
+	}
+	if n < 0 || int(n) >= length {
+		merror.Runtime(bracket, fmt.Sprintf("Index %v out of range.", n))
+	}
+	return int(n)
+}
+
+func (p *Interpreter) VisitSetIndexExpression(expression *ast.SetIndex) interface{} {
+	target := p.evaluate(expression.Object)
+	key := p.evaluate(expression.Key)
+	value := p.evaluate(expression.Value)
+	switch v := target.(type) {
+	case *object.List:
+		v.Elements[p.listIndex(expression.Bracket, key, len(v.Elements))] = value
+	case *object.Map:
+		if !object.IsHashable(key) {
+			merror.Runtime(expression.Bracket, "Map keys must be strings, numbers, booleans or nil.")
+		}
+		v.Set(key, value)
+	default:
+		merror.Runtime(expression.Bracket, "Only list elements and map entries can be assigned.")
+	}
+	return value
+}
+
+func (p *Interpreter) VisitGetExpression(expression *ast.Get) interface{} {
+	value := p.evaluate(expression.Object)
+	if instance, ok := value.(object.Instance); ok {
+		if property, ok := instance.Property(expression.Name.Lexeme); ok {
+			return property
+		}
+	}
+	if method, ok := callable.MethodOf(value, expression.Name.Lexeme); ok {
+		return method
+	}
+	merror.Runtime(expression.Name, fmt.Sprintf("Undefined property '%s'.", expression.Name.Lexeme))
+	return nil
+}
+
+func (p *Interpreter) VisitSetExpression(expression *ast.Set) interface{} {
+	target := p.evaluate(expression.Object)
+	value := p.evaluate(expression.Value)
+	setter, ok := target.(object.PropertySetter)
+	if !ok {
+		merror.Runtime(expression.Name, "Only maps and objects with settable properties can be assigned.")
+	}
+	if err := setter.SetProperty(expression.Name.Lexeme, value); err != nil {
+		merror.Runtime(expression.Name, err.Error())
+	}
 	return value
 }
 
@@ -210,30 +511,70 @@ func (p *Interpreter) VisitStmtExpressionStatement(statement *ast.StmtExpression
 }
 func (p *Interpreter) VisitPrintStatement(statement *ast.Print) interface{} {
 	value := p.evaluate(statement.Expr)
-	fmt.Printf("%+v\n", value)
+	fmt.Fprintf(output{p}, "%+v\n", value)
 	return nil
 }
 
 func (p *Interpreter) VisitWhileStatement(statement *ast.While) interface{} {
-	for p.expressBoolean(p.evaluate(statement.Condition)) {
+	for {
+		p.checkContext(statement.Span())
+		if !p.expressBoolean(p.evaluate(statement.Condition)) {
+			break
+		}
 		p.execute(statement.Body)
 	}
 	return nil
 }
 
+func (p *Interpreter) VisitForStatement(statement *ast.For) interface{} {
+	iterator := p.iterator(p.evaluate(statement.Iterable), statement.Name)
+	for {
+		p.checkContext(statement.Span())
+		item, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		// Every iteration gets its own binding so closures created in the
+		// body keep the value they saw.
+		loopEnv := environment.NewEnvironmentWithParent(p.environment)
+		loopEnv.Define(statement.Name.Lexeme, item)
+		p.executeBlock([]ast.Statement{statement.Body}, loopEnv)
+	}
+	return nil
+}
+
+func (p *Interpreter) iterator(value interface{}, tok token.Token) object.Iterator {
+	switch i := value.(type) {
+	case object.Iterable:
+		return i.Iterator()
+	case string:
+		return object.NewStringIterator(i)
+	default:
+		merror.Runtime(tok, "Can only iterate over lists, maps, ranges and strings.")
+	}
+	return nil
+}
+
 func (p *Interpreter) VisitVarStatement(statement *ast.Var) interface{} {
 	var value interface{} = nil
 	if statement.Initializer != nil {
 		value = p.evaluate(statement.Initializer)
 	}
 	p.environment.Define(statement.Name.Lexeme, value)
-	_, err := p.programState.Transition(environment.GIVEN)
-	_ = err
+	// A Given in a function body declares a local; it is not a step, and
+	// must not move the state of the step the function is called from.
+	if p.functionDepth == 0 {
+		p.programState.Transition(environment.GIVEN)
+	}
 	return nil
 }
 
 func (p *Interpreter) VisitWhenStatement(statement *ast.When) interface{} {
-	p.executeWhen(statement)
+	if statement.Body != nil {
+		p.execute(statement.Body)
+	} else {
+		p.executeWhen(statement)
+	}
 	_, err := p.programState.Transition(environment.WHEN)
 	_ = err
 	return nil
@@ -241,33 +582,82 @@ func (p *Interpreter) VisitWhenStatement(statement *ast.When) interface{} {
 
 func (p *Interpreter) executeWhen(statement *ast.When) {
 	value := p.evaluate(statement.Expr)
-	fmt.Printf("%+v\n", value)
+	fmt.Fprintf(output{p}, "%+v\n", value)
 }
 func (p *Interpreter) VisitThenStatement(statement *ast.Then) interface{} {
-	p.executeThen(statement)
+	if body, ok := statement.Body.(*ast.Block); ok {
+		p.execute(assertions(statement, body))
+	} else if statement.Body != nil {
+		p.execute(statement.Body)
+	} else {
+		p.executeThen(statement)
+	}
 	_, err := p.programState.Transition(environment.THEN)
 	_ = err
 	return nil
 }
 
+// assertions turns each expression statement of a `Then:` block into a
+// Then of its own, so it is asserted rather than only evaluated.
+func assertions(statement *ast.Then, body *ast.Block) *ast.Block {
+	statements := make([]ast.Statement, len(body.Statements))
+	for i, stmt := range body.Statements {
+		if expr, ok := stmt.(*ast.StmtExpression); ok {
+			keyword := statement.Keyword
+			keyword.Line = expr.Span().Start.Line
+			assertion := ast.NewThen(keyword, expr.Expr, nil)
+			assertion.SetSpan(expr.Span())
+			stmt = assertion
+		}
+		statements[i] = stmt
+	}
+	block := ast.NewBlock(statements, body.BlockState)
+	block.SetSpan(body.Span())
+	return block
+}
+
 func (p *Interpreter) executeThen(statement *ast.Then) {
-	value := p.evaluate(statement.Expr)
-	result := p.expressBoolean(value)
-	fmt.Printf("%+v\n", value)
-	fmt.Printf("%+v\n", result)
+	failure := &result.AssertionFailure{
+		Scenario:   p.label,
+		Line:       statement.Keyword.Line,
+		Expression: source(statement.Expr),
+	}
+	var value interface{}
+	if binary, ok := statement.Expr.(*ast.Binary); ok {
+		right := p.evaluate(binary.Right)
+		left := p.evaluate(binary.Left)
+		value = p.binary(binary.Operator, left, right)
+		failure.HasOperands = true
+		failure.Operator = binary.Operator.Lexeme
+		failure.Left = left
+		failure.Right = right
+	} else {
+		value = p.evaluate(statement.Expr)
+	}
+	if p.expressBoolean(value) {
+		return
+	}
+
+	p.failures = append(p.failures, failure)
+	if p.step != nil {
+		p.step.Status = result.FAILED
+		p.step.Failure = failure
+	}
+	if p.Policy == StopScenario {
+		p.stopped = true
+	}
 }
 
 func (p *Interpreter) VisitAndStatement(statement *ast.And) interface{} {
 	if p.programState.IsState(environment.WHEN) {
-		p.executeWhen(&ast.When{Expr: statement.Expr})
+		p.executeWhen(ast.NewWhen(statement.Keyword, statement.Expr, nil))
 	} else if p.programState.IsState(environment.THEN) {
-		p.executeThen(&ast.Then{Expr: statement.Expr})
+		p.executeThen(ast.NewThen(statement.Keyword, statement.Expr, nil))
 	} else if p.programState.IsState(environment.GIVEN) {
 		var varExpr interface{} = statement.Expr
-		fmt.Printf("current state %v\n", p.programState.CurrentState())
 		p.VisitAssignExpression(varExpr.(*ast.Assign))
 	} else {
-		fmt.Printf("AND: Program in invalid State:%+v\n", p.programState)
+		fmt.Fprintf(p.Diagnostics, "AND: Program in invalid State:%+v\n", p.programState)
 	}
 	return nil
 }
@@ -276,12 +666,17 @@ func (p *Interpreter) VisitScenarioStatement(statement *ast.Scenario) interface{
 	_, err := p.programState.Transition(environment.SCENARIO)
 	_ = err
 	p.label = statement.Label
+	p.startScenario(statement)
 	return nil
 }
 
 func (p *Interpreter) VisitBlockStatement(statement *ast.Block) interface{} {
-	_, err := p.programState.Transition(statement.BlockState)
-	_ = err
+	// Blocks inside a function body carry the state the function was declared
+	// in, not the step it is called from, so they must not move the program state.
+	if p.functionDepth == 0 {
+		_, err := p.programState.Transition(statement.BlockState)
+		_ = err
+	}
 	p.executeBlock(statement.Statements, environment.NewEnvironmentWithParent(p.environment))
 	return nil
 }
@@ -297,6 +692,46 @@ func (p *Interpreter) executeBlock(statements []ast.Statement, environment *envi
 	}
 }
 
+func (p *Interpreter) VisitFunctionStatement(statement *ast.Function) interface{} {
+	p.environment.Define(statement.Name.Lexeme, NewFunction(statement, p.environment, p))
+	return nil
+}
+
+func (p *Interpreter) VisitReturnStatement(statement *ast.Return) interface{} {
+	var value interface{} = nil
+	if statement.Value != nil {
+		value = p.evaluate(statement.Value)
+	}
+	panic(&returnValue{value: value})
+}
+
+// VisitImportStatement runs the function and Given declarations of the
+// imported module, and its own imports, the first time it is imported.
+func (p *Interpreter) VisitImportStatement(statement *ast.Import) interface{} {
+	module := statement.Module
+	if module == nil {
+		merror.Runtime(statement.Path, fmt.Sprintf("Module %q has not been loaded.", statement.Path.Literal))
+	}
+	if p.imported[module.File] {
+		return nil
+	}
+	p.imported[module.File] = true
+	// The module's declarations are not steps of the scenario the import
+	// follows, and run even when that scenario has stopped.
+	scenario, stopped := p.scenario, p.stopped
+	p.scenario, p.stopped = nil, false
+	defer func() {
+		p.scenario, p.stopped = scenario, stopped
+	}()
+	for _, stmt := range module.Statements {
+		switch stmt.(type) {
+		case *ast.Function, *ast.Var, *ast.Import:
+			p.execute(stmt)
+		}
+	}
+	return nil
+}
+
 //Convenience function to silently ignore newlines
 func (p *Interpreter) VisitDoNotingStatement(statement *ast.DoNoting) interface{} {
 	return nil
@@ -307,12 +742,5 @@ func (p *Interpreter) evaluate(expr ast.Expression) interface{} {
 }
 
 func (p *Interpreter) isEqual(a interface{}, b interface{}) bool {
-	if a == nil && b == nil {
-		return true
-	}
-	if a == nil {
-		return false
-	}
-
-	return a == b
+	return object.Equal(a, b)
 }
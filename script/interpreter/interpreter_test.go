@@ -0,0 +1,723 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/callable"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/parser"
+	"github.com/itsert/ofin/script/resolver"
+	"github.com/itsert/ofin/script/result"
+	"github.com/itsert/ofin/script/token"
+)
+
+func interpret(t *testing.T, input string) *Interpreter {
+	t.Helper()
+	return interpretWith(t, NewInterpreter(), input)
+}
+
+func interpretWith(t *testing.T, p *Interpreter, input string) *Interpreter {
+	t.Helper()
+	stmnts, err := parser.NewParser(lexer.NewLexer(input, "interpreter-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected parse error %v", err)
+	}
+	if err := resolver.NewResolver(p, "interpreter-test.ac").Resolve(stmnts); err != nil {
+		t.Fatalf("unexpected resolve error %v", err)
+	}
+	p.Interpret(context.Background(), stmnts)
+	return p
+}
+
+func global(t *testing.T, p *Interpreter, name string) interface{} {
+	t.Helper()
+	v, err := p.Global.Get(token.Token{Type: token.IDENTIFIER, Lexeme: name})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return v
+}
+
+func TestWithFunctionCall(t *testing.T) {
+	input := `fn fib(n):
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+
+fn nothing():
+    return
+
+Given a = fib(10)
+And b = nothing()
+`
+	p := interpret(t, input)
+	if v := global(t, p, "a"); v != 55.0 {
+		t.Fatalf("a wrong. expected=%v, got=%v", 55.0, v)
+	}
+	if v := global(t, p, "b"); v != nil {
+		t.Fatalf("b wrong. expected=nil, got=%v", v)
+	}
+}
+
+func TestWithClosure(t *testing.T) {
+	input := `fn makeCounter():
+    Given count = 0
+    fn increment():
+        count = count + 1
+        return count
+    return increment
+
+Given counter = makeCounter()
+And other = makeCounter()
+And a = counter()
+And b = counter()
+And c = other()
+`
+	p := interpret(t, input)
+	if v := global(t, p, "b"); v != 2.0 {
+		t.Fatalf("b wrong. expected=%v, got=%v", 2.0, v)
+	}
+	if v := global(t, p, "c"); v != 1.0 {
+		t.Fatalf("c wrong. expected=%v, got=%v", 1.0, v)
+	}
+}
+
+func TestWithStaticScope(t *testing.T) {
+	input := `Given a = "global"
+fn outer():
+    fn show():
+        return a
+    Given first = show()
+    Given a = "local"
+    return first + show()
+
+Given result = outer()
+`
+	p := interpret(t, input)
+	if v := global(t, p, "result"); v != "globalglobal" {
+		t.Fatalf("result wrong. expected=%q, got=%v", "globalglobal", v)
+	}
+}
+
+func TestWithForLoop(t *testing.T) {
+	input := `fn sum(n):
+    Given total = 0
+    for i in range(0, n):
+        total = total + i
+    return total
+
+fn join(word):
+    Given out = ""
+    for c in word:
+        out = c + out
+    return out
+
+Given a = sum(5)
+And b = join("abc")
+`
+	p := interpret(t, input)
+	if v := global(t, p, "a"); v != 10.0 {
+		t.Fatalf("a wrong. expected=%v, got=%v", 10.0, v)
+	}
+	if v := global(t, p, "b"); v != "cba" {
+		t.Fatalf("b wrong. expected=%q, got=%v", "cba", v)
+	}
+}
+
+func TestWithListsAndMaps(t *testing.T) {
+	input := `fn build():
+    Given xs = [1, 2, 3]
+    Given m = {"name": "ofin"}
+    xs[0] = 10
+    m["tags"] = ["a", "b"]
+    m["size"] = len(xs)
+    return m
+
+Given m = build()
+And same = m == {"name": "ofin", "tags": ["a", "b"], "size": 3}
+And different = [1, 2] != [1, 2, 3]
+And tag = m["tags"][1]
+`
+	p := interpret(t, input)
+	if v := global(t, p, "same"); v != true {
+		t.Fatalf("same wrong. expected=%v, got=%v", true, v)
+	}
+	if v := global(t, p, "different"); v != true {
+		t.Fatalf("different wrong. expected=%v, got=%v", true, v)
+	}
+	if v := global(t, p, "tag"); v != "b" {
+		t.Fatalf("tag wrong. expected=%q, got=%v", "b", v)
+	}
+}
+
+type response struct {
+	status float64
+}
+
+func (r *response) Property(name string) (interface{}, bool) {
+	if name == "status" {
+		return r.status, true
+	}
+	return nil, false
+}
+
+func TestWithPropertiesAndMethods(t *testing.T) {
+	input := `Given xs = [1, 2]
+And m = {"a": 1}
+And upper = " ofin ".trim().upper()
+And status = resp.status
+When xs.append(xs.len() + 1)
+And m.b = m.a + 1
+`
+	p := NewInterpreter()
+	p.Global.Define("resp", &response{status: 200})
+	interpretWith(t, p, input)
+
+	if v := global(t, p, "upper"); v != "OFIN" {
+		t.Fatalf("upper wrong. expected=%q, got=%v", "OFIN", v)
+	}
+	if v := fmt.Sprint(global(t, p, "xs")); v != "[1, 2, 3]" {
+		t.Fatalf("xs wrong. expected=%q, got=%v", "[1, 2, 3]", v)
+	}
+	if v := fmt.Sprint(global(t, p, "m")); v != `{"a": 1, "b": 2}` {
+		t.Fatalf("m wrong. expected=%q, got=%v", `{"a": 1, "b": 2}`, v)
+	}
+	if v := global(t, p, "status"); v != 200.0 {
+		t.Fatalf("status wrong. expected=%v, got=%v", 200.0, v)
+	}
+}
+
+func TestWithNatives(t *testing.T) {
+	natives := callable.NewRegistry()
+	natives.MustRegister("sum", func(xs ...int) int {
+		total := 0
+		for _, x := range xs {
+			total += x
+		}
+		return total
+	})
+	natives.MustRegister("tags", func(prefix string, m map[string]bool) []string {
+		tags := []string{}
+		for name, on := range m {
+			if on {
+				tags = append(tags, prefix+name)
+			}
+		}
+		return tags
+	})
+	natives.MustRegister("fail", func(message string) error {
+		return errors.New(message)
+	})
+	natives.MustRegister("handler", func() func() {
+		return func() {}
+	})
+	if err := natives.Register("Given", func() {}); err == nil {
+		t.Fatalf("expected an error registering a keyword")
+	}
+	if err := natives.Register("nope", 42); err == nil {
+		t.Fatalf("expected an error registering a number")
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+		err      string
+	}{
+		{"Given v = sum()\n", 0.0, ""},
+		{"Given v = sum(1, 2, 3)\n", 6.0, ""},
+		{"Given v = tags(\"#\", {\"a\": true, \"b\": false})\n", `["#a"]`, ""},
+		{"Given v = sum(1.5)\n", nil, "sum: argument 1 must be a whole number, got 1.5"},
+		{"Given v = sum(\"a\")\n", nil, `sum: argument 1 must be a number, got "a"`},
+		{"Given v = tags(\"#\")\n", nil, "Expected 2 arguments, but got 1. "},
+		{"Given v = fail(\"broken\")\n", nil, "broken"},
+		{"Given v = handler() == handler()\n", false, ""},
+	}
+	for i, tt := range tests {
+		stmnts, err := parser.NewParser(lexer.NewLexer(tt.input, "interpreter-test.ac")).ParseProgram()
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected parse error %v", i, err)
+		}
+		p := NewInterpreter()
+		natives.Define(p.Global)
+		err = p.Interpret(context.Background(), stmnts)
+		if tt.err != "" {
+			runtimeError, ok := err.(*merror.RuntimeError)
+			if !ok || runtimeError.Message != tt.err {
+				t.Fatalf("tests[%d] - error wrong. expected=%q, got=%v", i, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error %v", i, err)
+		}
+		v := global(t, p, "v")
+		if list, ok := v.(*object.List); ok {
+			v = list.String()
+		}
+		if v != tt.expected {
+			t.Fatalf("tests[%d] - v wrong. expected=%v, got=%v", i, tt.expected, v)
+		}
+	}
+}
+
+func TestWithOptionalArguments(t *testing.T) {
+	input := `fn greet(name, greeting = "hello", suffix = name.upper()):
+    return greeting + " " + name + " " + suffix
+
+fn collect(x, xs = []):
+    xs.append(x)
+    return xs
+
+Given full = greet("ann", "hi", "!")
+And short = greet("bob")
+And first = collect(1)
+And second = collect(2)
+`
+	p := interpret(t, input)
+	tests := []struct {
+		name     string
+		expected interface{}
+	}{
+		{"full", "hi ann !"},
+		{"short", "hello bob BOB"},
+		{"first", "[1]"},
+		{"second", "[2]"},
+	}
+	for _, tt := range tests {
+		if v := fmt.Sprint(global(t, p, tt.name)); v != tt.expected {
+			t.Fatalf("%s wrong. expected=%v, got=%v", tt.name, tt.expected, v)
+		}
+	}
+
+	ranges := []struct {
+		input    string
+		expected string
+	}{
+		{"range(3)", "[0, 1, 2]"},
+		{"range(2, 4)", "[2, 3]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+	}
+	for i, tt := range ranges {
+		p := NewInterpreter()
+		p.Global.Define("v", object.NewList(nil))
+		interpretWith(t, p, "fn f():\n    for i in "+tt.input+":\n        v.append(i)\nf()\n")
+		if v := fmt.Sprint(global(t, p, "v")); v != tt.expected {
+			t.Fatalf("ranges[%d] - v wrong. expected=%v, got=%v", i, tt.expected, v)
+		}
+	}
+
+	failures := []struct {
+		input    string
+		expected string
+	}{
+		{"fn f(a, b = 1):\n    return a\nGiven v = f()\n", "Expected 1 to 2 arguments, but got 0. "},
+		{"Given v = range(1, 2, 3, 4)\n", "Expected 1 to 3 arguments, but got 4. "},
+		{"Given v = range(1, 2, 0)\n", "range step must not be zero"},
+	}
+	for i, tt := range failures {
+		stmnts, err := parser.NewParser(lexer.NewLexer(tt.input, "interpreter-test.ac")).ParseProgram()
+		if err != nil {
+			t.Fatalf("failures[%d] - unexpected parse error %v", i, err)
+		}
+		p := NewInterpreter()
+		resolver.NewResolver(p, "interpreter-test.ac").Resolve(stmnts)
+		err = p.Interpret(context.Background(), stmnts)
+		runtimeError, ok := err.(*merror.RuntimeError)
+		if !ok || runtimeError.Message != tt.expected {
+			t.Fatalf("failures[%d] - error wrong. expected=%q, got=%v", i, tt.expected, err)
+		}
+	}
+}
+
+func TestWithCapturedOutput(t *testing.T) {
+	input := `print "before"
+Scenario "first":
+    Given a = 1
+    When a + 1
+    print_all("a is", a, [a])
+Scenario "second":
+    Given b = 2
+`
+	tests := []struct {
+		capture  bool
+		output   string
+		scenario string
+	}{
+		{false, "before\n2\na is 1 [1]\n", ""},
+		{true, "before\n", "2\na is 1 [1]\n"},
+	}
+	for i, tt := range tests {
+		var out bytes.Buffer
+		p := NewInterpreter()
+		p.Output = &out
+		p.Capture = tt.capture
+		interpretWith(t, p, input)
+		if out.String() != tt.output {
+			t.Fatalf("tests[%d] - output wrong. expected=%q, got=%q", i, tt.output, out.String())
+		}
+		scenarios := p.Results().Scenarios
+		if scenarios[0].Output != tt.scenario || scenarios[1].Output != "" {
+			t.Fatalf("tests[%d] - scenario output wrong. expected=%q, got=%q and %q", i, tt.scenario, scenarios[0].Output, scenarios[1].Output)
+		}
+	}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	input := `fn spin():
+    Given i = 0
+    while true:
+        i = i + 1
+Scenario "hangs":
+    Given a = 1
+    When spin()
+    Then a == 1
+Scenario "passes":
+    Given b = 2
+    Then b == 2
+`
+	stmnts, err := parser.NewParser(lexer.NewLexer(input, "interpreter-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected parse error %v", err)
+	}
+
+	p := NewInterpreter()
+	resolver.NewResolver(p, "interpreter-test.ac").Resolve(stmnts)
+	p.ScenarioTimeout = 20 * time.Millisecond
+	if err := p.Interpret(context.Background(), stmnts); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	scenarios := p.Results().Scenarios
+	if len(scenarios) != 2 || scenarios[0].Status != result.ERRORED || scenarios[1].Status != result.PASSED {
+		t.Fatalf("scenarios wrong. expected one errored and one passed, got=%+v", scenarios)
+	}
+	if msg := scenarios[0].ErrorMessage(); msg != "interpreter-test.ac:3:5 scenario timed out after 20ms" {
+		t.Fatalf("error wrong. expected=%q, got=%q", "interpreter-test.ac:3:5 scenario timed out after 20ms", msg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	p = NewInterpreter()
+	resolver.NewResolver(p, "interpreter-test.ac").Resolve(stmnts)
+	err = p.Interpret(ctx, stmnts)
+	runtimeError, ok := err.(*merror.RuntimeError)
+	if !ok || runtimeError.Line != 3 || runtimeError.Message != "run timed out" {
+		t.Fatalf("error wrong. expected run timed out at line 3, got=%v", err)
+	}
+	scenarios = p.Results().Scenarios
+	if len(scenarios) != 1 || scenarios[0].Status != result.ERRORED {
+		t.Fatalf("scenarios wrong. expected only the errored one, got=%+v", scenarios)
+	}
+}
+
+func TestWithFailingAssertions(t *testing.T) {
+	input := `Scenario "stops":
+    Given a = 3
+    Then a == 5
+    And a > 10
+
+Scenario "passes":
+    Given b = [1, 2]
+    Then b == [1, 2]
+`
+	p := interpret(t, input)
+	failures := p.Failures()
+	if len(failures) != 1 {
+		t.Fatalf("failures wrong. expected=%d, got=%d", 1, len(failures))
+	}
+	failure := failures[0]
+	if failure.Scenario != "stops" || failure.Line != 3 || failure.Expression != "a == 5" {
+		t.Fatalf("failure wrong. got=%+v", failure)
+	}
+	if !failure.HasOperands || failure.Left != 3.0 || failure.Right != 5.0 {
+		t.Fatalf("operands wrong. got=%+v", failure)
+	}
+}
+
+func TestWithThenBlock(t *testing.T) {
+	input := `Scenario "block":
+    Given a = 1
+    Then:
+        a == 1
+        a == 100
+`
+	p := interpret(t, input)
+	failures := p.Failures()
+	if len(failures) != 1 {
+		t.Fatalf("failures wrong. expected=%d, got=%d", 1, len(failures))
+	}
+	if failures[0].Line != 5 || failures[0].Expression != "a == 100" {
+		t.Fatalf("failure wrong. got=%+v", failures[0])
+	}
+	if status := p.Results().Scenarios[0].Status; status != result.FAILED {
+		t.Fatalf("status wrong. expected=%q, got=%q", result.FAILED, status)
+	}
+}
+
+func TestWithBlockStepText(t *testing.T) {
+	input := `Scenario "blocks":
+    Given a = 1
+    When:
+        a = a + 1
+        print a
+        a
+    Then:
+        a == 2
+    When:
+        print a
+`
+	p := interpret(t, input)
+	tests := []struct {
+		keyword string
+		text    string
+	}{
+		{"Given", "a = 1"},
+		{"When", "a = a + 1; a"},
+		{"Then", "a == 2"},
+		{"When", "When:"},
+	}
+	steps := p.Results().Scenarios[0].Steps
+	if len(steps) != len(tests) {
+		t.Fatalf("steps wrong. expected=%d, got=%d", len(tests), len(steps))
+	}
+	for i, tt := range tests {
+		if steps[i].Keyword != tt.keyword || steps[i].Text != tt.text {
+			t.Fatalf("tests[%d] - step wrong. expected=%s %q, got=%s %q",
+				i, tt.keyword, tt.text, steps[i].Keyword, steps[i].Text)
+		}
+	}
+}
+
+func TestWithDeclarationAfterStoppedScenario(t *testing.T) {
+	input := `Scenario "a":
+    Given x = 1
+    Then x == 2
+fn helper():
+    return 1
+Scenario "b":
+    Then helper() == 1
+`
+	run := interpret(t, input).Results()
+	if len(run.Scenarios) != 2 {
+		t.Fatalf("scenarios wrong. expected=%d, got=%d", 2, len(run.Scenarios))
+	}
+	if status := run.Scenarios[0].Status; status != result.FAILED {
+		t.Fatalf("scenario a wrong. expected=%q, got=%q", result.FAILED, status)
+	}
+	if b := run.Scenarios[1]; b.Status != result.PASSED {
+		t.Fatalf("scenario b wrong. expected=%q, got=%q %s", result.PASSED, b.Status, b.Error)
+	}
+}
+
+func TestWithLocalDeclaredInStep(t *testing.T) {
+	p := NewInterpreter()
+	natives := callable.NewRegistry()
+	natives.MustRegister("state", func() string {
+		return string(p.State())
+	})
+	natives.Define(p.Global)
+	input := `fn make():
+    Given local = 1
+    return local
+Scenario "state":
+    Then [make(), state()] == [1, "SCENARIO"]
+`
+	interpretWith(t, p, input)
+	if failures := p.Failures(); len(failures) != 0 {
+		t.Fatalf("a local moved the scenario state. got=%v", failures[0])
+	}
+}
+
+func TestWithContinueOnFailure(t *testing.T) {
+	input := `Scenario "continues":
+    Given a = 3
+    Then a == 5
+    And a > 10
+    And a == 3
+`
+	p := NewInterpreter()
+	p.Policy = ContinueScenario
+	interpretWith(t, p, input)
+
+	failures := p.Failures()
+	if len(failures) != 2 {
+		t.Fatalf("failures wrong. expected=%d, got=%d", 2, len(failures))
+	}
+	if failures[1].Expression != "a > 10" || failures[1].Line != 4 {
+		t.Fatalf("failure wrong. got=%+v", failures[1])
+	}
+}
+
+func TestWithScenarioResults(t *testing.T) {
+	input := `Scenario "failing":
+    Given a = 3
+    Then a == 5
+    And a > 10
+
+Scenario "errors":
+    Given b = 1
+    When b = nope + 1
+    Then b == 1
+
+Scenario "passing":
+    Given c = [1, 2]
+    When:
+        c.append(3)
+    Then c == [1, 2, 3]
+`
+	run := interpret(t, input).Results()
+	tests := []struct {
+		name   string
+		status result.Status
+		steps  []result.Status
+	}{
+		{"failing", result.FAILED, []result.Status{result.PASSED, result.FAILED, result.SKIPPED}},
+		{"errors", result.ERRORED, []result.Status{result.PASSED, result.ERRORED, result.SKIPPED}},
+		{"passing", result.PASSED, []result.Status{result.PASSED, result.PASSED, result.PASSED}},
+	}
+
+	if len(run.Scenarios) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d", len(tests), len(run.Scenarios))
+	}
+	for i, tt := range tests {
+		scenario := run.Scenarios[i]
+		if scenario.Name != tt.name || scenario.Status != tt.status {
+			t.Fatalf("tests[%d] - scenario wrong. expected=%s %s, got=%s %s",
+				i, tt.name, tt.status, scenario.Name, scenario.Status)
+		}
+		if len(scenario.Steps) != len(tt.steps) {
+			t.Fatalf("tests[%d] - steps wrong. expected=%d, got=%d", i, len(tt.steps), len(scenario.Steps))
+		}
+		for j, status := range tt.steps {
+			if scenario.Steps[j].Status != status {
+				t.Fatalf("tests[%d] - step %d status wrong. expected=%s, got=%s",
+					i, j, status, scenario.Steps[j].Status)
+			}
+		}
+	}
+	if step := run.Scenarios[0].Steps[0]; step.Keyword != "Given" || step.Text != "a = 3" || step.Line != 2 {
+		t.Fatalf("step wrong. got=%+v", step)
+	}
+}
+
+func TestWithRuntimeError(t *testing.T) {
+	input := `Given a = 1
+print a
+print nope
+print a
+`
+	stmnts, err := parser.NewParser(lexer.NewLexer(input, "interpreter-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected parse error %v", err)
+	}
+	p := NewInterpreter()
+	p.File = "interpreter-test.ac"
+	err = p.Interpret(context.Background(), stmnts)
+
+	runtimeError, ok := err.(*merror.RuntimeError)
+	if !ok {
+		t.Fatalf("error wrong. expected=*merror.RuntimeError, got=%T", err)
+	}
+	if runtimeError.File != "interpreter-test.ac" || runtimeError.Line != 3 {
+		t.Fatalf("position wrong. expected=%s:%d, got=%s:%d", "interpreter-test.ac", 3, runtimeError.File, runtimeError.Line)
+	}
+	if runtimeError.Token.Lexeme != "nope" {
+		t.Fatalf("token wrong. expected=%q, got=%q", "nope", runtimeError.Token.Lexeme)
+	}
+	if runtimeError.Message != "variable nope is undefined" {
+		t.Fatalf("message wrong. expected=%q, got=%q", "variable nope is undefined", runtimeError.Message)
+	}
+}
+
+func TestWithImport(t *testing.T) {
+	files := map[string]string{
+		"lib.ac": `Given base = 10
+
+fn add_base(x):
+    return x + base
+
+Scenario "library scenarios are not run":
+    Then false
+`,
+		"main.ac": `import "lib.ac"
+import "lib.ac"
+
+Scenario "uses the library":
+    Given a = add_base(1)
+    Then a == 11
+`,
+	}
+	l := loader.NewLoader()
+	l.ReadFile = func(path string) ([]byte, error) {
+		return []byte(files[path]), nil
+	}
+	module, err := l.Load("main.ac")
+	if err != nil {
+		t.Fatalf("unexpected load error %v", err)
+	}
+	p := NewInterpreter()
+	if err := resolver.NewResolver(p, module.File).Resolve(module.Statements); err != nil {
+		t.Fatalf("unexpected resolve error %v", err)
+	}
+	if err := p.Interpret(context.Background(), module.Statements); err != nil {
+		t.Fatalf("unexpected runtime error %v", err)
+	}
+
+	run := p.Results()
+	if len(run.Scenarios) != 1 || run.Scenarios[0].Status != result.PASSED {
+		t.Fatalf("scenarios wrong. expected=1 passed, got=%+v", run.Scenarios)
+	}
+	if base := global(t, p, "base"); base != 10.0 {
+		t.Fatalf("base wrong. expected=%v, got=%v", 10.0, base)
+	}
+}
+
+func TestWithImportAfterScenario(t *testing.T) {
+	files := map[string]string{
+		"lib.ac": `Given base = 10
+
+fn add_base(x):
+    return x + base
+`,
+		"main.ac": `Scenario "stops":
+    Then false
+import "lib.ac"
+Scenario "uses the library":
+    Given a = add_base(1)
+    Then a == 11
+`,
+	}
+	l := loader.NewLoader()
+	l.ReadFile = func(path string) ([]byte, error) {
+		return []byte(files[path]), nil
+	}
+	module, err := l.Load("main.ac")
+	if err != nil {
+		t.Fatalf("unexpected load error %v", err)
+	}
+	p := NewInterpreter()
+	if err := resolver.NewResolver(p, module.File).Resolve(module.Statements); err != nil {
+		t.Fatalf("unexpected resolve error %v", err)
+	}
+	if err := p.Interpret(context.Background(), module.Statements); err != nil {
+		t.Fatalf("unexpected runtime error %v", err)
+	}
+
+	run := p.Results()
+	if len(run.Scenarios) != 2 {
+		t.Fatalf("scenarios wrong. expected=%d, got=%d", 2, len(run.Scenarios))
+	}
+	if stops := run.Scenarios[0]; stops.Status != result.FAILED || len(stops.Steps) != 1 {
+		t.Fatalf("scenario stops wrong. expected=failed with 1 step, got=%s with %d", stops.Status, len(stops.Steps))
+	}
+	if uses := run.Scenarios[1]; uses.Status != result.PASSED {
+		t.Fatalf("scenario uses wrong. expected=%q, got=%q %s", result.PASSED, uses.Status, uses.Error)
+	}
+}
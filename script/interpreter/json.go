@@ -0,0 +1,163 @@
+package interpreter
+
+import (
+	"encoding/json"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/token"
+)
+
+// node is the JSON form of a syntax tree node. Besides its fields, keyed as
+// in the ast package, it names the node type and carries its span.
+type node map[string]interface{}
+
+// JSONPrinter serializes the syntax tree, with the position of every node
+// and token, for tools that consume the AST.
+type JSONPrinter struct {
+}
+
+func NewJSONPrinter() *JSONPrinter {
+	return &JSONPrinter{}
+}
+
+// Marshal encodes statements as an indented JSON array.
+func (j *JSONPrinter) Marshal(statements []ast.Statement) ([]byte, error) {
+	return json.MarshalIndent(j.statements(statements), "", "  ")
+}
+
+// spanned is satisfied by every expression and statement.
+type spanned interface {
+	Span() token.Span
+}
+
+func (j *JSONPrinter) node(n spanned, name string, fields node) node {
+	fields["Node"] = name
+	fields["Span"] = n.Span()
+	return fields
+}
+
+func (j *JSONPrinter) expr(expr ast.Expression) interface{} {
+	if expr == nil {
+		return nil
+	}
+	return expr.Accept(j)
+}
+
+func (j *JSONPrinter) exprs(exprs []ast.Expression) []interface{} {
+	nodes := []interface{}{}
+	for _, expr := range exprs {
+		nodes = append(nodes, j.expr(expr))
+	}
+	return nodes
+}
+
+func (j *JSONPrinter) stmt(statement ast.Statement) interface{} {
+	if statement == nil {
+		return nil
+	}
+	return statement.Accept(j)
+}
+
+func (j *JSONPrinter) statements(statements []ast.Statement) []interface{} {
+	nodes := []interface{}{}
+	for _, statement := range statements {
+		nodes = append(nodes, j.stmt(statement))
+	}
+	return nodes
+}
+
+func (j *JSONPrinter) VisitAssignExpression(expr *ast.Assign) interface{} {
+	return j.node(expr, "Assign", node{"Name": expr.Name, "Expr": j.expr(expr.Expr)})
+}
+func (j *JSONPrinter) VisitBinaryExpression(expr *ast.Binary) interface{} {
+	return j.node(expr, "Binary", node{"Left": j.expr(expr.Left), "Operator": expr.Operator, "Right": j.expr(expr.Right)})
+}
+func (j *JSONPrinter) VisitCallExpression(expr *ast.Call) interface{} {
+	return j.node(expr, "Call", node{"Callee": j.expr(expr.Callee), "Paren": expr.Paren, "Arguments": j.exprs(expr.Arguments)})
+}
+func (j *JSONPrinter) VisitGroupingExpression(expr *ast.Grouping) interface{} {
+	return j.node(expr, "Grouping", node{"Expr": j.expr(expr.Expr)})
+}
+func (j *JSONPrinter) VisitLiteralExpression(expr *ast.Literal) interface{} {
+	return j.node(expr, "Literal", node{"Value": expr.Value})
+}
+func (j *JSONPrinter) VisitLogicalExpression(expr *ast.Logical) interface{} {
+	return j.node(expr, "Logical", node{"Left": j.expr(expr.Left), "Operator": expr.Operator, "Right": j.expr(expr.Right)})
+}
+func (j *JSONPrinter) VisitUnaryExpression(expr *ast.Unary) interface{} {
+	return j.node(expr, "Unary", node{"Operator": expr.Operator, "Right": j.expr(expr.Right)})
+}
+func (j *JSONPrinter) VisitVariableExpression(expr *ast.Variable) interface{} {
+	return j.node(expr, "Variable", node{"Name": expr.Name})
+}
+func (j *JSONPrinter) VisitListExpression(expr *ast.List) interface{} {
+	return j.node(expr, "List", node{"Bracket": expr.Bracket, "Elements": j.exprs(expr.Elements)})
+}
+func (j *JSONPrinter) VisitMapExpression(expr *ast.Map) interface{} {
+	return j.node(expr, "Map", node{"Brace": expr.Brace, "Keys": j.exprs(expr.Keys), "Values": j.exprs(expr.Values)})
+}
+func (j *JSONPrinter) VisitIndexExpression(expr *ast.Index) interface{} {
+	return j.node(expr, "Index", node{"Object": j.expr(expr.Object), "Bracket": expr.Bracket, "Key": j.expr(expr.Key)})
+}
+func (j *JSONPrinter) VisitSetIndexExpression(expr *ast.SetIndex) interface{} {
+	return j.node(expr, "SetIndex", node{"Object": j.expr(expr.Object), "Bracket": expr.Bracket, "Key": j.expr(expr.Key), "Value": j.expr(expr.Value)})
+}
+func (j *JSONPrinter) VisitGetExpression(expr *ast.Get) interface{} {
+	return j.node(expr, "Get", node{"Object": j.expr(expr.Object), "Name": expr.Name})
+}
+func (j *JSONPrinter) VisitSetExpression(expr *ast.Set) interface{} {
+	return j.node(expr, "Set", node{"Object": j.expr(expr.Object), "Name": expr.Name, "Value": j.expr(expr.Value)})
+}
+
+func (j *JSONPrinter) VisitStmtExpressionStatement(statement *ast.StmtExpression) interface{} {
+	return j.node(statement, "StmtExpression", node{"Expr": j.expr(statement.Expr)})
+}
+func (j *JSONPrinter) VisitIfStatement(statement *ast.If) interface{} {
+	return j.node(statement, "If", node{"Condition": j.expr(statement.Condition), "ThenBranch": j.stmt(statement.ThenBranch), "ElseBranch": j.stmt(statement.ElseBranch)})
+}
+func (j *JSONPrinter) VisitPrintStatement(statement *ast.Print) interface{} {
+	return j.node(statement, "Print", node{"Expr": j.expr(statement.Expr)})
+}
+func (j *JSONPrinter) VisitWhenStatement(statement *ast.When) interface{} {
+	return j.node(statement, "When", node{"Keyword": statement.Keyword, "Expr": j.expr(statement.Expr), "Body": j.stmt(statement.Body)})
+}
+func (j *JSONPrinter) VisitThenStatement(statement *ast.Then) interface{} {
+	return j.node(statement, "Then", node{"Keyword": statement.Keyword, "Expr": j.expr(statement.Expr), "Body": j.stmt(statement.Body)})
+}
+func (j *JSONPrinter) VisitAndStatement(statement *ast.And) interface{} {
+	return j.node(statement, "And", node{"Keyword": statement.Keyword, "Expr": j.expr(statement.Expr)})
+}
+func (j *JSONPrinter) VisitScenarioStatement(statement *ast.Scenario) interface{} {
+	return j.node(statement, "Scenario", node{"Keyword": statement.Keyword, "Label": statement.Label})
+}
+func (j *JSONPrinter) VisitVarStatement(statement *ast.Var) interface{} {
+	return j.node(statement, "Var", node{"Keyword": statement.Keyword, "Name": statement.Name, "Initializer": j.expr(statement.Initializer)})
+}
+func (j *JSONPrinter) VisitWhileStatement(statement *ast.While) interface{} {
+	return j.node(statement, "While", node{"Condition": j.expr(statement.Condition), "Body": j.stmt(statement.Body)})
+}
+func (j *JSONPrinter) VisitBlockStatement(statement *ast.Block) interface{} {
+	return j.node(statement, "Block", node{"Statements": j.statements(statement.Statements), "BlockState": statement.BlockState})
+}
+func (j *JSONPrinter) VisitDoNotingStatement(statement *ast.DoNoting) interface{} {
+	return j.node(statement, "DoNoting", node{"Name": statement.Name})
+}
+func (j *JSONPrinter) VisitFunctionStatement(statement *ast.Function) interface{} {
+	return j.node(statement, "Function", node{"Name": statement.Name, "Params": statement.Params, "Defaults": j.exprs(statement.Defaults), "Body": j.statements(statement.Body)})
+}
+func (j *JSONPrinter) VisitReturnStatement(statement *ast.Return) interface{} {
+	return j.node(statement, "Return", node{"Keyword": statement.Keyword, "Value": j.expr(statement.Value)})
+}
+func (j *JSONPrinter) VisitForStatement(statement *ast.For) interface{} {
+	return j.node(statement, "For", node{"Name": statement.Name, "Iterable": j.expr(statement.Iterable), "Body": j.stmt(statement.Body)})
+}
+
+// VisitImportStatement names the imported script rather than embedding it,
+// as a module may be imported many times.
+func (j *JSONPrinter) VisitImportStatement(statement *ast.Import) interface{} {
+	var module interface{}
+	if statement.Module != nil {
+		module = statement.Module.File
+	}
+	return j.node(statement, "Import", node{"Keyword": statement.Keyword, "Path": statement.Path, "Module": module})
+}
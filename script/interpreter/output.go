@@ -0,0 +1,14 @@
+package interpreter
+
+// output is the writer print, When and print_all write to. While Capture
+// is set, what a scenario prints is kept for its result instead.
+type output struct {
+	p *Interpreter
+}
+
+func (o output) Write(b []byte) (int, error) {
+	if o.p.Capture && o.p.scenario != nil {
+		return o.p.captured.Write(b)
+	}
+	return o.p.Output.Write(b)
+}
@@ -1,49 +1,198 @@
 package interpreter
 
-// import (
-// 	"fmt"
-// 	"strings"
-
-// 	"github.com/itsert/ofin/script/ast"
-// )
-
-// type prettyInterpreter struct {
-// }
-
-// func (p *prettyInterpreter) Print(expr ast.Expression) interface{} {
-// 	return expr.Accept(p)
-// }
-
-// func NewPrettyPrinter() *prettyInterpreter {
-// 	return &prettyInterpreter{}
-// }
-
-// func (p *prettyInterpreter) VisitBinaryExpression(expr *ast.Binary) interface{} {
-// 	return p.parenthesize(expr.Operator.Lexeme, expr.Left, expr.Right)
-// }
-// func (p *prettyInterpreter) VisitGroupingExpression(expr *ast.Grouping) interface{} {
-// 	return p.parenthesize("group", expr.Expr)
-// }
-// func (p *prettyInterpreter) VisitLiteralExpression(expr *ast.Literal) interface{} {
-// 	if expr.Value == nil {
-// 		return "nil"
-// 	}
-// 	return fmt.Sprintf("%+v", expr.Value)
-// }
-// func (p *prettyInterpreter) VisitUnaryExpression(expr *ast.Unary) interface{} {
-// 	return p.parenthesize(expr.Operator.Lexeme, expr.Right)
-// }
-
-// func (p *prettyInterpreter) parenthesize(name string, exprs ...ast.Expression) string {
-// 	var builder strings.Builder
-
-// 	builder.WriteString("(")
-// 	builder.WriteString(name)
-// 	for _, expr := range exprs {
-// 		builder.WriteString(" ")
-// 		builder.WriteString(fmt.Sprintf("%+v", expr.Accept(p)))
-// 	}
-// 	builder.WriteString(")")
-
-// 	return builder.String()
-// }
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itsert/ofin/script/ast"
+)
+
+// PrettyPrinter renders the syntax tree as parenthesized prefix expressions,
+// which makes the grouping the parser chose explicit.
+type PrettyPrinter struct {
+}
+
+func NewPrettyPrinter() *PrettyPrinter {
+	return &PrettyPrinter{}
+}
+
+func (p *PrettyPrinter) Print(expr ast.Expression) string {
+	return expr.Accept(p).(string)
+}
+
+// PrintStatements renders each statement on a line of its own.
+func (p *PrettyPrinter) PrintStatements(statements []ast.Statement) string {
+	var builder strings.Builder
+	for _, statement := range statements {
+		builder.WriteString(p.statement(statement))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (p *PrettyPrinter) statement(statement ast.Statement) string {
+	return statement.Accept(p).(string)
+}
+
+func (p *PrettyPrinter) VisitAssignExpression(expr *ast.Assign) interface{} {
+	return p.parenthesize("=", expr.Name.Lexeme, expr.Expr)
+}
+func (p *PrettyPrinter) VisitBinaryExpression(expr *ast.Binary) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, expr.Left, expr.Right)
+}
+func (p *PrettyPrinter) VisitCallExpression(expr *ast.Call) interface{} {
+	parts := []interface{}{expr.Callee}
+	for _, argument := range expr.Arguments {
+		parts = append(parts, argument)
+	}
+	return p.parenthesize("call", parts...)
+}
+func (p *PrettyPrinter) VisitGroupingExpression(expr *ast.Grouping) interface{} {
+	return p.parenthesize("group", expr.Expr)
+}
+func (p *PrettyPrinter) VisitLiteralExpression(expr *ast.Literal) interface{} {
+	switch value := expr.Value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprintf("%+v", expr.Value)
+}
+func (p *PrettyPrinter) VisitLogicalExpression(expr *ast.Logical) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, expr.Left, expr.Right)
+}
+func (p *PrettyPrinter) VisitUnaryExpression(expr *ast.Unary) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, expr.Right)
+}
+func (p *PrettyPrinter) VisitVariableExpression(expr *ast.Variable) interface{} {
+	return expr.Name.Lexeme
+}
+func (p *PrettyPrinter) VisitListExpression(expr *ast.List) interface{} {
+	var parts []interface{}
+	for _, element := range expr.Elements {
+		parts = append(parts, element)
+	}
+	return p.parenthesize("list", parts...)
+}
+func (p *PrettyPrinter) VisitMapExpression(expr *ast.Map) interface{} {
+	var parts []interface{}
+	for i, key := range expr.Keys {
+		parts = append(parts, p.parenthesize(":", key, expr.Values[i]))
+	}
+	return p.parenthesize("map", parts...)
+}
+func (p *PrettyPrinter) VisitIndexExpression(expr *ast.Index) interface{} {
+	return p.parenthesize("index", expr.Object, expr.Key)
+}
+func (p *PrettyPrinter) VisitSetIndexExpression(expr *ast.SetIndex) interface{} {
+	return p.parenthesize("set-index", expr.Object, expr.Key, expr.Value)
+}
+func (p *PrettyPrinter) VisitGetExpression(expr *ast.Get) interface{} {
+	return p.parenthesize(".", expr.Object, expr.Name.Lexeme)
+}
+func (p *PrettyPrinter) VisitSetExpression(expr *ast.Set) interface{} {
+	return p.parenthesize("set", expr.Object, expr.Name.Lexeme, expr.Value)
+}
+
+func (p *PrettyPrinter) VisitStmtExpressionStatement(statement *ast.StmtExpression) interface{} {
+	return p.parenthesize(";", statement.Expr)
+}
+func (p *PrettyPrinter) VisitIfStatement(statement *ast.If) interface{} {
+	if statement.ElseBranch == nil {
+		return p.parenthesize("if", statement.Condition, statement.ThenBranch)
+	}
+	return p.parenthesize("if", statement.Condition, statement.ThenBranch, statement.ElseBranch)
+}
+func (p *PrettyPrinter) VisitPrintStatement(statement *ast.Print) interface{} {
+	return p.parenthesize("print", statement.Expr)
+}
+func (p *PrettyPrinter) VisitWhenStatement(statement *ast.When) interface{} {
+	return p.step(statement.Keyword.Lexeme, statement.Expr, statement.Body)
+}
+func (p *PrettyPrinter) VisitThenStatement(statement *ast.Then) interface{} {
+	return p.step(statement.Keyword.Lexeme, statement.Expr, statement.Body)
+}
+func (p *PrettyPrinter) VisitAndStatement(statement *ast.And) interface{} {
+	return p.parenthesize(statement.Keyword.Lexeme, statement.Expr)
+}
+func (p *PrettyPrinter) VisitScenarioStatement(statement *ast.Scenario) interface{} {
+	return p.parenthesize("Scenario", fmt.Sprintf("%q", statement.Label))
+}
+func (p *PrettyPrinter) VisitVarStatement(statement *ast.Var) interface{} {
+	if statement.Initializer == nil {
+		return p.parenthesize(statement.Keyword.Lexeme, statement.Name.Lexeme)
+	}
+	return p.parenthesize(statement.Keyword.Lexeme, statement.Name.Lexeme, statement.Initializer)
+}
+func (p *PrettyPrinter) VisitWhileStatement(statement *ast.While) interface{} {
+	return p.parenthesize("while", statement.Condition, statement.Body)
+}
+func (p *PrettyPrinter) VisitBlockStatement(statement *ast.Block) interface{} {
+	var parts []interface{}
+	for _, s := range statement.Statements {
+		parts = append(parts, s)
+	}
+	return p.parenthesize("block", parts...)
+}
+func (p *PrettyPrinter) VisitDoNotingStatement(statement *ast.DoNoting) interface{} {
+	return "(nop)"
+}
+func (p *PrettyPrinter) VisitFunctionStatement(statement *ast.Function) interface{} {
+	var params []string
+	for i, param := range statement.Params {
+		if statement.Defaults[i] != nil {
+			params = append(params, p.parenthesize("=", param.Lexeme, statement.Defaults[i]))
+			continue
+		}
+		params = append(params, param.Lexeme)
+	}
+	parts := []interface{}{statement.Name.Lexeme, "(" + strings.Join(params, " ") + ")"}
+	for _, s := range statement.Body {
+		parts = append(parts, s)
+	}
+	return p.parenthesize("fn", parts...)
+}
+func (p *PrettyPrinter) VisitReturnStatement(statement *ast.Return) interface{} {
+	if statement.Value == nil {
+		return "(return)"
+	}
+	return p.parenthesize("return", statement.Value)
+}
+func (p *PrettyPrinter) VisitForStatement(statement *ast.For) interface{} {
+	return p.parenthesize("for", statement.Name.Lexeme, statement.Iterable, statement.Body)
+}
+func (p *PrettyPrinter) VisitImportStatement(statement *ast.Import) interface{} {
+	return p.parenthesize("import", statement.Path.Lexeme)
+}
+
+// step renders a When or Then, which holds either an expression or a block.
+func (p *PrettyPrinter) step(keyword string, expr ast.Expression, body ast.Statement) string {
+	if body != nil {
+		return p.parenthesize(keyword, body)
+	}
+	return p.parenthesize(keyword, expr)
+}
+
+// parenthesize wraps name and parts in parentheses. A part is an expression,
+// a statement or text written as is.
+func (p *PrettyPrinter) parenthesize(name string, parts ...interface{}) string {
+	var builder strings.Builder
+
+	builder.WriteString("(")
+	builder.WriteString(name)
+	for _, part := range parts {
+		builder.WriteString(" ")
+		switch part := part.(type) {
+		case ast.Expression:
+			builder.WriteString(p.Print(part))
+		case ast.Statement:
+			builder.WriteString(p.statement(part))
+		default:
+			builder.WriteString(fmt.Sprintf("%+v", part))
+		}
+	}
+	builder.WriteString(")")
+
+	return builder.String()
+}
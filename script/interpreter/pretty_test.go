@@ -0,0 +1,79 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/parser"
+)
+
+func parse(t *testing.T, input string) []ast.Statement {
+	t.Helper()
+	stmnts, err := parser.NewParser(lexer.NewLexer(input, "pretty-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected parse error %v", err)
+	}
+	return stmnts
+}
+
+func TestPrettyPrinter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Given a = -1 + 2 * (3 - nil)\n", "(Given a (+ (- 1) (* 2 (group (- 3 nil)))))\n"},
+		{"Given l = [1, \"x\", true]\n", "(Given l (list 1 \"x\" true))\n"},
+		{"Given m = {\"k\": 1}\n", "(Given m (map (: \"k\" 1)))\n"},
+		{"l[0] = m[\"k\"]\n", "(; (set-index l 0 (index m \"k\")))\n"},
+		{"o.x = o.y\n", "(; (set o x (. o y)))\n"},
+		{"a = f(1, 2) or !b and c\n", "(; (= a (or (call f 1 2) (and (! b) c))))\n"},
+		{"fn f(x, y):\n    return x\nprint f\n", "(fn f (x y) (return x))\n(print f)\n"},
+		{"fn g():\n    return\n", "(fn g () (return))\n"},
+		{"fn w():\n    while a < 3:\n        a = a + 1\n", "(fn w () (while (< a 3) (block (; (= a (+ a 1))))))\n"},
+		{"fn e(l):\n    for x in l:\n        print x\n", "(fn e (l) (for x l (block (print x))))\n"},
+		{"fn i(a):\n    if a:\n        print 1\n    else:\n        print 2\n", "(fn i (a) (if a (block (print 1)) (block (print 2))))\n"},
+		{"import \"lib.ac\"\n", "(import \"lib.ac\")\n"},
+		{
+			"Scenario \"s\":\n    Given a = 1\n    When a = 2\n    Then a == 2\n    And a > 1\n",
+			"(Scenario \"s\")\n(block (Given a 1) (When (= a 2)) (Then (== a 2)) (And (> a 1)))\n",
+		},
+	}
+
+	for i, tt := range tests {
+		printed := NewPrettyPrinter().PrintStatements(parse(t, tt.input))
+		if printed != tt.expected {
+			t.Fatalf("tests[%d] - printed wrong. expected=%q, got=%q", i, tt.expected, printed)
+		}
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	out, err := NewJSONPrinter().Marshal(parse(t, "Given a = 1 + 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var nodes []map[string]interface{}
+	if err := json.Unmarshal(out, &nodes); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(nodes) != 1 || nodes[0]["Node"] != "Var" {
+		t.Fatalf("nodes wrong. got=%s", out)
+	}
+
+	binary := nodes[0]["Initializer"].(map[string]interface{})
+	if binary["Node"] != "Binary" {
+		t.Fatalf("initializer wrong. expected=%q, got=%v", "Binary", binary["Node"])
+	}
+	span := binary["Span"].(map[string]interface{})
+	start := span["Start"].(map[string]interface{})
+	end := span["End"].(map[string]interface{})
+	if span["File"] != "pretty-test.ac" || start["Column"] != 11.0 || end["Column"] != 16.0 {
+		t.Fatalf("span wrong. expected=pretty-test.ac 11-16, got=%v %v-%v", span["File"], start["Column"], end["Column"])
+	}
+	right := binary["Right"].(map[string]interface{})
+	if right["Value"] != 2.0 {
+		t.Fatalf("value wrong. expected=%v, got=%v", 2.0, right["Value"])
+	}
+}
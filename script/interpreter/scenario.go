@@ -0,0 +1,126 @@
+package interpreter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/result"
+	"github.com/itsert/ofin/script/token"
+)
+
+func (p *Interpreter) startScenario(statement *ast.Scenario) {
+	p.finishScenario()
+	p.scenario = result.NewScenario(statement.Label, statement.Keyword.Line)
+	p.results.Scenarios = append(p.results.Scenarios, p.scenario)
+	p.scenarioStart = time.Now()
+	p.stopped = false
+	p.captured.Reset()
+	p.startScenarioContext()
+}
+
+func (p *Interpreter) finishScenario() {
+	if p.scenario == nil {
+		return
+	}
+	p.stopScenarioContext()
+	p.scenario.Finish(time.Since(p.scenarioStart))
+	if p.Capture {
+		p.scenario.Output = p.captured.String()
+		p.captured.Reset()
+	}
+	p.scenario = nil
+	p.stopped = false
+}
+
+// atStepLevel reports whether a Given, When, Then or And executed now is a
+// step of the current scenario rather than part of an enclosing step or of
+// a function body.
+func (p *Interpreter) atStepLevel() bool {
+	return p.scenario != nil && p.step == nil && p.functionDepth == 0
+}
+
+// alwaysRuns reports whether stmt runs even once the current scenario has
+// stopped: a Scenario starts the next one, and functions and imports are
+// declarations later scenarios may depend on.
+func alwaysRuns(stmt ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.Scenario, *ast.Function, *ast.Import:
+		return true
+	}
+	return false
+}
+
+// stepOf returns the keyword and text of statements that make up a step.
+func (p *Interpreter) stepOf(stmt ast.Statement) (token.Token, string, bool) {
+	switch s := stmt.(type) {
+	case *ast.Var:
+		if s.Initializer == nil {
+			return s.Keyword, s.Name.Lexeme, true
+		}
+		return s.Keyword, s.Name.Lexeme + " = " + source(s.Initializer), true
+	case *ast.When:
+		if s.Expr == nil {
+			return s.Keyword, blockText(s.Keyword, s.Body), true
+		}
+		return s.Keyword, source(s.Expr), true
+	case *ast.Then:
+		if s.Expr == nil {
+			return s.Keyword, blockText(s.Keyword, s.Body), true
+		}
+		return s.Keyword, source(s.Expr), true
+	case *ast.And:
+		return s.Keyword, source(s.Expr), true
+	}
+	return token.Token{}, "", false
+}
+
+// blockText is the text of a When: or Then: block step: its expressions
+// joined by semicolons, or the keyword itself when it has none.
+func blockText(keyword token.Token, body ast.Statement) string {
+	parts := []string{}
+	if block, ok := body.(*ast.Block); ok {
+		for _, stmt := range block.Statements {
+			if expr, ok := stmt.(*ast.StmtExpression); ok {
+				parts = append(parts, source(expr.Expr))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return keyword.Lexeme + ":"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// runStep executes stmt as a step of the current scenario and records its
+// outcome. Once the scenario is stopped the step is only recorded as skipped.
+func (p *Interpreter) runStep(stmt ast.Statement, keyword token.Token, text string) {
+	step := result.NewStep(keyword.Lexeme, text, keyword.Line)
+	p.scenario.Steps = append(p.scenario.Steps, step)
+	if p.stopped {
+		step.Status = result.SKIPPED
+		return
+	}
+
+	p.step = step
+	start := time.Now()
+	defer func() {
+		step.Duration = time.Since(start)
+		p.step = nil
+		if r := recover(); r != nil {
+			step.Status = result.ERRORED
+			step.Error = p.errorMessage(r, step.Line)
+			p.stopped = true
+			// Once the run is done the error stops the script, not only
+			// the scenario.
+			if p.ctx.Err() != nil {
+				panic(r)
+			}
+			return
+		}
+		if step.Status == "" {
+			step.Status = result.PASSED
+		}
+	}()
+	stmt.Accept(p)
+}
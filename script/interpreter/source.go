@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"strings"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/object"
+)
+
+// sourcePrinter turns an expression back into the text it was parsed from,
+// modulo whitespace, so assertion failures can quote what was written.
+type sourcePrinter struct{}
+
+func source(expr ast.Expression) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.Accept(sourcePrinter{}).(string)
+}
+
+func (s sourcePrinter) join(exprs []ast.Expression) string {
+	parts := []string{}
+	for _, expr := range exprs {
+		parts = append(parts, source(expr))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s sourcePrinter) VisitAssignExpression(expression *ast.Assign) interface{} {
+	return expression.Name.Lexeme + " = " + source(expression.Expr)
+}
+
+func (s sourcePrinter) VisitBinaryExpression(expression *ast.Binary) interface{} {
+	return source(expression.Left) + " " + expression.Operator.Lexeme + " " + source(expression.Right)
+}
+
+func (s sourcePrinter) VisitCallExpression(expression *ast.Call) interface{} {
+	return source(expression.Callee) + "(" + s.join(expression.Arguments) + ")"
+}
+
+func (s sourcePrinter) VisitGroupingExpression(expression *ast.Grouping) interface{} {
+	return "(" + source(expression.Expr) + ")"
+}
+
+func (s sourcePrinter) VisitLiteralExpression(expression *ast.Literal) interface{} {
+	return object.Inspect(expression.Value)
+}
+
+func (s sourcePrinter) VisitLogicalExpression(expression *ast.Logical) interface{} {
+	return source(expression.Left) + " " + expression.Operator.Lexeme + " " + source(expression.Right)
+}
+
+func (s sourcePrinter) VisitUnaryExpression(expression *ast.Unary) interface{} {
+	return expression.Operator.Lexeme + source(expression.Right)
+}
+
+func (s sourcePrinter) VisitVariableExpression(expression *ast.Variable) interface{} {
+	return expression.Name.Lexeme
+}
+
+func (s sourcePrinter) VisitListExpression(expression *ast.List) interface{} {
+	return "[" + s.join(expression.Elements) + "]"
+}
+
+func (s sourcePrinter) VisitMapExpression(expression *ast.Map) interface{} {
+	parts := []string{}
+	for i := range expression.Keys {
+		parts = append(parts, source(expression.Keys[i])+": "+source(expression.Values[i]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (s sourcePrinter) VisitIndexExpression(expression *ast.Index) interface{} {
+	return source(expression.Object) + "[" + source(expression.Key) + "]"
+}
+
+func (s sourcePrinter) VisitSetIndexExpression(expression *ast.SetIndex) interface{} {
+	return source(expression.Object) + "[" + source(expression.Key) + "] = " + source(expression.Value)
+}
+
+func (s sourcePrinter) VisitGetExpression(expression *ast.Get) interface{} {
+	return source(expression.Object) + "." + expression.Name.Lexeme
+}
+
+func (s sourcePrinter) VisitSetExpression(expression *ast.Set) interface{} {
+	return source(expression.Object) + "." + expression.Name.Lexeme + " = " + source(expression.Value)
+}
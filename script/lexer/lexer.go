@@ -16,11 +16,16 @@ type Lexer struct {
 	start             int
 	current           int
 	line              int
+	lineStart         int
 	tokens            []token.Token
 	File              string
 	indentTokenLength int
 	indentTokenStack  stack.Stack
 	whiteSpaceType    byte
+	errors            merror.ErrorList
+	// comments are kept apart from the tokens, which the parser never sees
+	// them in.
+	comments []token.Token
 }
 
 func NewLexer(input string, fileName string) *Lexer {
@@ -35,28 +40,75 @@ func NewLexer(input string, fileName string) *Lexer {
 	return lexer
 }
 
-func (s *Lexer) Tokenize() []token.Token {
+// Tokenize scans the whole input. A malformed token is skipped and scanning
+// carries on, so the error, if any, is a merror.ErrorList of every problem
+// found.
+func (s *Lexer) Tokenize() ([]token.Token, error) {
 	for !s.end() {
 		s.start = s.current
-		s.munchToken()
+		s.munch()
 	}
 
-	s.tokens = append(s.tokens, token.Token{
-		Type:    token.EOF,
-		Lexeme:  "",
-		Literal: nil,
-		Line:    s.line,
+	s.start = s.current
+	s.addToken(token.EOF, nil)
+	return s.tokens, s.errors.Err()
+}
+
+// munch scans a single token, recording a syntax error instead of stopping.
+func (s *Lexer) munch() {
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxError, ok := r.(*merror.SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			s.errors = append(s.errors, syntaxError)
+		}
+	}()
+	s.munchToken()
+}
+
+// Comments returns the comments scanned so far, in source order.
+func (s *Lexer) Comments() []token.Token {
+	return s.comments
+}
+
+func (s *Lexer) addComment() {
+	s.comments = append(s.comments, token.Token{
+		Type:      token.COMMENT,
+		Lexeme:    s.input[s.start:s.current],
+		Line:      s.line,
+		Column:    s.column(),
+		EndColumn: s.current - s.lineStart + 1,
+		Start:     s.start,
+		End:       s.current,
+		File:      s.File,
 	})
-	return s.tokens
+}
+
+// column is the 1-based column the current token starts at.
+func (s *Lexer) column() int {
+	return s.start - s.lineStart + 1
 }
 
 func (s *Lexer) addToken(tokenType token.TokenType, literal interface{}) {
 	text := s.input[s.start:s.current]
+	// INDENT and DEDENT are added once the line break before them has been
+	// read; they are placed at the start of the new line.
+	start := s.start
+	if start < s.lineStart {
+		start = s.lineStart
+	}
 	s.tokens = append(s.tokens, token.Token{
-		Type:    tokenType,
-		Lexeme:  text,
-		Literal: literal,
-		Line:    s.line,
+		Type:      tokenType,
+		Lexeme:    text,
+		Literal:   literal,
+		Line:      s.line,
+		Column:    start - s.lineStart + 1,
+		EndColumn: s.current - s.lineStart + 1,
+		Start:     start,
+		End:       s.current,
+		File:      s.File,
 	})
 }
 
@@ -95,6 +147,10 @@ func (s *Lexer) munchToken() {
 		s.addToken(token.LEFT_BRACE, nil)
 	case '}':
 		s.addToken(token.RIGHT_BRACE, nil)
+	case '[':
+		s.addToken(token.LEFT_BRACKET, nil)
+	case ']':
+		s.addToken(token.RIGHT_BRACKET, nil)
 	case ',':
 		s.addToken(token.COMMA, nil)
 	case '.':
@@ -147,14 +203,16 @@ func (s *Lexer) munchToken() {
 			for s.peek() != '\n' && !s.end() {
 				s.advance()
 			}
+			s.addComment()
 		} else {
 			s.addToken(token.SLASH, nil)
 		}
 	case '\n', '\r':
-		s.line += 1
 		if len(s.tokens) > 0 && s.lastToken().Type != token.NEWLINE {
 			s.addToken(token.NEWLINE, nil)
 		}
+		s.line += 1
+		s.lineStart = s.current
 		if s.peekNext() == COMMENT_MARKER || s.peekNext() == '\n' || s.peekNext() == '\r' {
 			break
 		}
@@ -176,7 +234,7 @@ func (s *Lexer) munchToken() {
 		} else if isLetter(ch) {
 			s.eatIdentifier()
 		} else {
-			merror.Error(s.File, s.line, s.start, "unexpected character.")
+			merror.Error(s.File, s.line, s.column(), "unexpected character.")
 		}
 	}
 
@@ -184,6 +242,10 @@ func (s *Lexer) munchToken() {
 
 func (s *Lexer) processIndentBlocks() {
 	count, whiteSpaceType := s.eatWhiteSpaces()
+	// A line holding only a comment does not open or close a block.
+	if s.peek() == COMMENT_MARKER && s.peekNext() == COMMENT_MARKER {
+		return
+	}
 	if count > s.indentTokenStack.Peek().(int) {
 		if s.indentTokenLength == 0 {
 			s.indentTokenLength = count
@@ -194,14 +256,14 @@ func (s *Lexer) processIndentBlocks() {
 		if s.indentTokenStack.Size() > 1 {
 			nextCount := s.indentTokenStack.Peek().(int) + s.indentTokenLength
 			if nextCount != count || s.whiteSpaceType != whiteSpaceType {
-				merror.Error(s.File, s.line, s.start, "inconsistent indentation detected")
+				merror.Error(s.File, s.line, s.column(), "inconsistent indentation detected")
 				return
 			}
 		} else {
 			nextCount := s.indentTokenLength
 
 			if nextCount != count || s.whiteSpaceType != whiteSpaceType {
-				merror.Error(s.File, s.line, s.start, "inconsistent indentation detected")
+				merror.Error(s.File, s.line, s.column(), "inconsistent indentation detected")
 				return
 			}
 		}
@@ -212,7 +274,7 @@ func (s *Lexer) processIndentBlocks() {
 			for count < s.indentTokenStack.Peek().(int) {
 				nextCount := s.indentTokenLength * (s.indentTokenStack.Size() - 1)
 				if nextCount != s.indentTokenStack.Peek().(int) || s.whiteSpaceType != whiteSpaceType {
-					merror.Error(s.File, s.line, s.start, "inconsistent indentation detected")
+					merror.Error(s.File, s.line, s.column(), "inconsistent indentation detected")
 					return
 				}
 				s.addToken(token.DEDENT, nil)
@@ -284,20 +346,20 @@ func (s *Lexer) eatNumbers() {
 		s.addToken(token.NUMBER, f)
 	} else {
 		msg := fmt.Sprintf("Error parsing value %s", s.input[s.start:s.current])
-		merror.Error("test.of", s.line, s.start, msg)
+		merror.Error(s.File, s.line, s.column(), msg)
 	}
 }
 
 func (s *Lexer) eatString() {
 	for s.peek() != '"' && !s.end() {
 		if s.peek() == '\n' {
-			merror.Error(s.File, s.line, s.start, "String did not terminate before encountering newline")
+			merror.Error(s.File, s.line, s.column(), "String did not terminate before encountering newline")
 		}
 		s.advance()
 	}
 
 	if s.end() {
-		merror.Error(s.File, s.line, s.start, "String does not terminate")
+		merror.Error(s.File, s.line, s.column(), "String does not terminate")
 		return
 	}
 	s.advance()
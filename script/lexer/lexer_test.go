@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/itsert/ofin/merror"
 	"github.com/itsert/ofin/script/token"
 )
 
@@ -25,7 +26,7 @@ func TestWIthSimpleExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -71,7 +72,7 @@ func TestWIthCompoundExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -125,7 +126,7 @@ func TestWithFreeFormExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -161,7 +162,7 @@ func TestWithStringExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -197,7 +198,7 @@ func TestWithNumericalExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -234,7 +235,7 @@ func TestWithNewlineExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 	fmt.Printf("%+v", tokens)
 
 	if len(tokens) != len(tests) {
@@ -273,7 +274,7 @@ func TestWithMultiNewlineExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 	fmt.Printf("%+v", tokens)
 
 	if len(tokens) != len(tests) {
@@ -316,7 +317,7 @@ func TestWithIdentifierAndKeywordsExpression(t *testing.T) {
 	}
 
 	s := NewLexer(input, "lexer-test.go")
-	tokens := s.Tokenize()
+	tokens, _ := s.Tokenize()
 
 	if len(tokens) != len(tests) {
 		t.Fatalf("Length unmatching. expected=%d, got=%d",
@@ -336,3 +337,193 @@ func TestWithIdentifierAndKeywordsExpression(t *testing.T) {
 	}
 
 }
+
+func TestWithBracketExpression(t *testing.T) {
+	input := `xs[0] = [1, 2]`
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLexeme string
+	}{
+		{token.IDENTIFIER, "xs"},
+		{token.LEFT_BRACKET, "["},
+		{token.NUMBER, "0"},
+		{token.RIGHT_BRACKET, "]"},
+		{token.ASSIGN, "="},
+		{token.LEFT_BRACKET, "["},
+		{token.NUMBER, "1"},
+		{token.COMMA, ","},
+		{token.NUMBER, "2"},
+		{token.RIGHT_BRACKET, "]"},
+		{token.EOF, ""},
+	}
+
+	s := NewLexer(input, "lexer-test.go")
+	tokens, _ := s.Tokenize()
+
+	if len(tokens) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d",
+			len(tests), len(tokens))
+	}
+
+	for i := range tests {
+		if tokens[i].Type != tests[i].expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tests[i].expectedType, tokens[i].Type)
+		}
+
+		if tokens[i].Lexeme != tests[i].expectedLexeme {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tests[i].expectedLexeme, tokens[i].Lexeme)
+		}
+	}
+
+}
+
+func TestWithUnexpectedCharacters(t *testing.T) {
+	input := `Given a = 1 $
+Given b = "open
+Given c = 2 @
+`
+	tests := []struct {
+		line    int
+		column  int
+		message string
+	}{
+		{1, 13, "unexpected character."},
+		{2, 11, "String did not terminate before encountering newline"},
+		{3, 13, "unexpected character."},
+	}
+
+	s := NewLexer(input, "lexer-test.ac")
+	tokens, err := s.Tokenize()
+
+	errors, ok := err.(merror.ErrorList)
+	if !ok {
+		t.Fatalf("error wrong. expected=merror.ErrorList, got=%T", err)
+	}
+	if len(errors) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d", len(tests), len(errors))
+	}
+	for i, tt := range tests {
+		if errors[i].File != "lexer-test.ac" || errors[i].Line != tt.line || errors[i].Column != tt.column {
+			t.Fatalf("tests[%d] - position wrong. expected=%s:%d:%d, got=%s:%d:%d",
+				i, "lexer-test.ac", tt.line, tt.column, errors[i].File, errors[i].Line, errors[i].Column)
+		}
+		if errors[i].Message != tt.message {
+			t.Fatalf("tests[%d] - message wrong. expected=%q, got=%q", i, tt.message, errors[i].Message)
+		}
+	}
+
+	// Scanning carries on past each error.
+	var identifiers []string
+	for _, tok := range tokens {
+		if tok.Type == token.IDENTIFIER {
+			identifiers = append(identifiers, tok.Lexeme)
+		}
+	}
+	if fmt.Sprint(identifiers) != "[a b c]" {
+		t.Fatalf("identifiers wrong. expected=%v, got=%v", "[a b c]", identifiers)
+	}
+	if tokens[len(tokens)-1].Type != token.EOF {
+		t.Fatalf("last token wrong. expected=%q, got=%q", token.EOF, tokens[len(tokens)-1].Type)
+	}
+}
+
+func TestWithTokenPositions(t *testing.T) {
+	input := `Given a = 1
+Scenario "s":
+    Then a >= 1
+`
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedStart  int
+		expectedEnd    int
+	}{
+		{token.GIVEN, 1, 1, 0, 5},
+		{token.IDENTIFIER, 1, 7, 6, 7},
+		{token.ASSIGN, 1, 9, 8, 9},
+		{token.NUMBER, 1, 11, 10, 11},
+		{token.NEWLINE, 1, 12, 11, 12},
+		{token.SCENARIO, 2, 1, 12, 20},
+		{token.STRING, 2, 10, 21, 24},
+		{token.COLON, 2, 13, 24, 25},
+		{token.NEWLINE, 2, 14, 25, 26},
+		{token.INDENT, 3, 1, 26, 30},
+		{token.THEN, 3, 5, 30, 34},
+		{token.IDENTIFIER, 3, 10, 35, 36},
+		{token.GREATER_EQUAL, 3, 12, 37, 39},
+		{token.NUMBER, 3, 15, 40, 41},
+		{token.NEWLINE, 3, 16, 41, 42},
+		{token.DEDENT, 4, 1, 42, 42},
+		{token.EOF, 4, 1, 42, 42},
+	}
+
+	s := NewLexer(input, "lexer-test.ac")
+	tokens, err := s.Tokenize()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(tokens) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d", len(tests), len(tokens))
+	}
+	for i, tt := range tests {
+		tok := tokens[i]
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		if tok.Start != tt.expectedStart || tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - offsets wrong. expected=%d-%d, got=%d-%d",
+				i, tt.expectedStart, tt.expectedEnd, tok.Start, tok.End)
+		}
+		if tok.EndColumn != tok.Column+tok.End-tok.Start {
+			t.Fatalf("tests[%d] - end column wrong. expected=%d, got=%d",
+				i, tok.Column+tok.End-tok.Start, tok.EndColumn)
+		}
+		if tok.File != "lexer-test.ac" {
+			t.Fatalf("tests[%d] - file wrong. expected=%q, got=%q", i, "lexer-test.ac", tok.File)
+		}
+	}
+}
+
+func TestWithComments(t *testing.T) {
+	input := `// header
+Scenario "s":
+    Given a = 1 // trailing
+  // misaligned
+    Then a == 1
+`
+	tests := []struct {
+		expectedLexeme string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{"// header", 1, 1},
+		{"// trailing", 3, 17},
+		{"// misaligned", 4, 3},
+	}
+
+	s := NewLexer(input, "lexer-test.ac")
+	if _, err := s.Tokenize(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	comments := s.Comments()
+	if len(comments) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d", len(tests), len(comments))
+	}
+	for i, tt := range tests {
+		comment := comments[i]
+		if comment.Type != token.COMMENT || comment.Lexeme != tt.expectedLexeme {
+			t.Fatalf("tests[%d] - comment wrong. expected=%q, got=%q %q", i, tt.expectedLexeme, comment.Type, comment.Lexeme)
+		}
+		if comment.Line != tt.expectedLine || comment.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, comment.Line, comment.Column)
+		}
+	}
+}
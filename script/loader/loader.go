@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/parser"
+)
+
+// Loader parses scripts along with every script they import. A script is
+// parsed once however many scripts import it.
+type Loader struct {
+	// ReadFile reads a script; it defaults to os.ReadFile.
+	ReadFile func(path string) ([]byte, error)
+	modules  map[string]*ast.Module
+	sources  map[string]string
+	// loading is the chain of imports being followed, used to detect cycles.
+	loading []string
+}
+
+func NewLoader() *Loader {
+	return &Loader{
+		ReadFile: os.ReadFile,
+		modules:  map[string]*ast.Module{},
+		sources:  map[string]string{},
+	}
+}
+
+// Load parses the script at path and attaches to each of its import
+// statements, recursively, the module it names. The error, if any, is a
+// merror.ErrorList covering every script that failed to load.
+func (l *Loader) Load(path string) (*ast.Module, error) {
+	data, err := l.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.LoadSource(path, string(data))
+}
+
+// LoadSource is Load for a script whose text has already been read.
+func (l *Loader) LoadSource(path string, source string) (*ast.Module, error) {
+	path = filepath.Clean(path)
+	l.sources[path] = source
+	l.loading = append(l.loading, path)
+	defer func() {
+		l.loading = l.loading[:len(l.loading)-1]
+	}()
+
+	statements, err := parser.NewParser(lexer.NewLexer(source, path)).ParseProgram()
+	var errors merror.ErrorList
+	if err != nil {
+		errors = append(errors, err.(merror.ErrorList)...)
+	}
+	module := &ast.Module{
+		File:       path,
+		Statements: statements,
+	}
+	for _, stmt := range statements {
+		if statement, ok := stmt.(*ast.Import); ok {
+			if err := l.importModule(path, statement); err != nil {
+				errors = append(errors, err.(merror.ErrorList)...)
+			}
+		}
+	}
+	l.modules[path] = module
+	return module, errors.Err()
+}
+
+func (l *Loader) importModule(importer string, statement *ast.Import) error {
+	path := filepath.Join(filepath.Dir(importer), statement.Path.Literal.(string))
+	for i, loading := range l.loading {
+		if loading == path {
+			chain := append(append([]string{}, l.loading[i:]...), path)
+			return merror.ErrorList{importError(importer, statement, "import cycle: "+strings.Join(chain, " -> "))}
+		}
+	}
+	if module, ok := l.modules[path]; ok {
+		statement.Module = module
+		return nil
+	}
+
+	data, err := l.ReadFile(path)
+	if err != nil {
+		return merror.ErrorList{importError(importer, statement, fmt.Sprintf("cannot import %q: %v", statement.Path.Literal, err))}
+	}
+	module, err := l.LoadSource(path, string(data))
+	statement.Module = module
+	return err
+}
+
+func importError(importer string, statement *ast.Import, message string) *merror.SyntaxError {
+	return &merror.SyntaxError{
+		File:    importer,
+		Line:    statement.Path.Line,
+		Column:  statement.Path.Column,
+		Token:   statement.Path,
+		Message: message,
+	}
+}
+
+// Source is the text of a loaded script.
+func (l *Loader) Source(path string) string {
+	return l.sources[filepath.Clean(path)]
+}
+
+// Diagnostic renders err like merror.Diagnostic, quoting each error against
+// the script it was found in.
+func (l *Loader) Diagnostic(err error) string {
+	switch e := err.(type) {
+	case merror.ErrorList:
+		diagnostics := make([]string, len(e))
+		for i, syntaxError := range e {
+			diagnostics[i] = merror.Diagnostic(syntaxError, l.Source(syntaxError.File))
+		}
+		return strings.Join(diagnostics, "\n")
+	case *merror.SyntaxError:
+		return merror.Diagnostic(e, l.Source(e.File))
+	case *merror.RuntimeError:
+		return merror.Diagnostic(e, l.Source(e.File))
+	}
+	return err.Error()
+}
@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+)
+
+func testLoader(files map[string]string) *Loader {
+	l := NewLoader()
+	l.ReadFile = func(path string) ([]byte, error) {
+		source, ok := files[path]
+		if !ok {
+			return nil, fmt.Errorf("open %s: %w", path, os.ErrNotExist)
+		}
+		return []byte(source), nil
+	}
+	return l
+}
+
+func TestWithImports(t *testing.T) {
+	l := testLoader(map[string]string{
+		"features/main.ac":        "import \"lib/helpers.ac\"\nimport \"lib/base.ac\"\n",
+		"features/lib/helpers.ac": "import \"base.ac\"\nfn double(x):\n    return x * base\n",
+		"features/lib/base.ac":    "Given base = 2\n",
+	})
+	module, err := l.Load("features/main.ac")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	helpers := module.Statements[0].(*ast.Import).Module
+	base := module.Statements[1].(*ast.Import).Module
+	if helpers.File != "features/lib/helpers.ac" {
+		t.Fatalf("file wrong. expected=%q, got=%q", "features/lib/helpers.ac", helpers.File)
+	}
+	if base.File != "features/lib/base.ac" {
+		t.Fatalf("file wrong. expected=%q, got=%q", "features/lib/base.ac", base.File)
+	}
+	// base.ac is imported twice but parsed once.
+	if helpers.Statements[0].(*ast.Import).Module != base {
+		t.Fatalf("module not cached. expected=%p, got=%p", base, helpers.Statements[0].(*ast.Import).Module)
+	}
+	if l.Source("features/lib/base.ac") != "Given base = 2\n" {
+		t.Fatalf("source wrong. got=%q", l.Source("features/lib/base.ac"))
+	}
+}
+
+func TestWithImportErrors(t *testing.T) {
+	l := testLoader(map[string]string{
+		"a.ac": "import \"b.ac\"\nimport \"missing.ac\"\n",
+		"b.ac": "import \"a.ac\"\n",
+	})
+	_, err := l.Load("a.ac")
+
+	errors, ok := err.(merror.ErrorList)
+	if !ok {
+		t.Fatalf("error wrong. expected=merror.ErrorList, got=%T", err)
+	}
+	tests := []struct {
+		file    string
+		line    int
+		message string
+	}{
+		{"b.ac", 1, "import cycle: a.ac -> b.ac -> a.ac"},
+		{"a.ac", 2, "cannot import \"missing.ac\""},
+	}
+	if len(errors) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d (%v)", len(tests), len(errors), errors)
+	}
+	for i, tt := range tests {
+		if errors[i].File != tt.file || errors[i].Line != tt.line {
+			t.Fatalf("tests[%d] - position wrong. expected=%s:%d, got=%s:%d", i, tt.file, tt.line, errors[i].File, errors[i].Line)
+		}
+		if !strings.HasPrefix(errors[i].Message, tt.message) {
+			t.Fatalf("tests[%d] - message wrong. expected=%q, got=%q", i, tt.message, errors[i].Message)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/loader"
+	"github.com/itsert/ofin/script/resolver"
+	"github.com/itsert/ofin/script/token"
+)
+
+// keywords are offered by completion everywhere.
+var keywords = []string{"Scenario", "Given", "When", "Then", "And"}
+
+// native is a function every script can call without declaring it.
+type native struct {
+	name   string
+	detail string
+}
+
+func natives() []native {
+	values := interpreter.NewInterpreter().Global.Values()
+	var natives []native
+	for name, value := range values {
+		natives = append(natives, native{name: name, detail: fmt.Sprint(value)})
+	}
+	sort.Slice(natives, func(i, j int) bool {
+		return natives[i].name < natives[j].name
+	})
+	return natives
+}
+
+// document is an open script along with what analysing it found.
+type document struct {
+	uri  string
+	path string
+	text string
+	// lines holds the offset each line starts at.
+	lines        []int
+	tokens       []token.Token
+	statements   []ast.Statement
+	declarations []declaration
+	diagnostics  []Diagnostic
+}
+
+// analyze parses, resolves and collects the declarations of a script.
+// Scripts it imports are read through readFile.
+func analyze(uri string, text string, readFile func(path string) ([]byte, error)) *document {
+	d := &document{
+		uri:  uri,
+		path: filepath.Clean(uriToPath(uri)),
+		text: text,
+	}
+	d.lines = []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	d.tokens, _ = lexer.NewLexer(text, d.path).Tokenize()
+
+	modules := loader.NewLoader()
+	modules.ReadFile = readFile
+	module, err := modules.LoadSource(d.path, text)
+	d.statements = module.Statements
+	if err == nil {
+		err = resolver.NewResolver(interpreter.NewInterpreter(), module.File).Resolve(module.Statements)
+	}
+	if errors, ok := err.(merror.ErrorList); ok {
+		for _, syntaxError := range errors {
+			if diagnostic, ok := d.diagnostic(syntaxError); ok {
+				d.diagnostics = append(d.diagnostics, diagnostic)
+			}
+		}
+	}
+
+	source := func(file string) string {
+		if file == module.File {
+			return text
+		}
+		return modules.Source(file)
+	}
+	d.declarations = newScopes(source, len(text)).collect(d.statements)
+	return d
+}
+
+// diagnostic places an error in the document. An error in an imported
+// script is reported on the import that brought it in.
+func (d *document) diagnostic(err *merror.SyntaxError) (Diagnostic, bool) {
+	diagnostic := Diagnostic{Severity: SeverityError, Source: "ofin", Message: err.Message}
+	if err.File == d.path {
+		start := Position{Line: err.Line - 1, Character: max(err.Column-1, 0)}
+		end := Position{Line: start.Line, Character: start.Character + 1}
+		if err.Token.Line == err.Line && err.Token.EndColumn > err.Column {
+			end.Character = err.Token.EndColumn - 1
+		}
+		diagnostic.Range = Range{Start: start, End: end}
+		return diagnostic, true
+	}
+	for _, statement := range d.statements {
+		if statement, ok := statement.(*ast.Import); ok && statement.Module != nil && statement.Module.File == err.File {
+			diagnostic.Range = tokenRange(statement.Path)
+			diagnostic.Message = err.Error()
+			return diagnostic, true
+		}
+	}
+	return diagnostic, false
+}
+
+// offset converts a position in the document to a byte offset.
+func (d *document) offset(position Position) int {
+	if position.Line < 0 {
+		return 0
+	}
+	if position.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[position.Line] + position.Character
+	end := len(d.text)
+	if position.Line+1 < len(d.lines) {
+		end = d.lines[position.Line+1] - 1
+	}
+	if offset > end {
+		return end
+	}
+	return offset
+}
+
+// identifier is the identifier at offset, if there is one.
+func (d *document) identifier(offset int) (token.Token, bool) {
+	for _, tok := range d.tokens {
+		if tok.Type == token.IDENTIFIER && tok.Start <= offset && offset <= tok.End {
+			return tok, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// visible lists the declarations that can be used at offset, innermost
+// first.
+func (d *document) visible(offset int) []declaration {
+	var visible []declaration
+	for _, declaration := range d.declarations {
+		if declaration.from <= offset && offset <= declaration.to {
+			visible = append(visible, declaration)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].from > visible[j].from
+	})
+	return visible
+}
+
+// lookup finds the declaration the identifier at offset refers to.
+func (d *document) lookup(offset int) (token.Token, *declaration, bool) {
+	tok, ok := d.identifier(offset)
+	if !ok {
+		return tok, nil, false
+	}
+	for _, declaration := range d.visible(tok.Start) {
+		if declaration.name.Lexeme == tok.Lexeme {
+			return tok, &declaration, true
+		}
+	}
+	return tok, nil, true
+}
+
+func (d *document) completion(position Position, natives []native) []CompletionItem {
+	items := []CompletionItem{}
+	for _, keyword := range keywords {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionKeyword})
+	}
+	seen := map[string]bool{}
+	for _, declaration := range d.visible(d.offset(position)) {
+		if seen[declaration.name.Lexeme] {
+			continue
+		}
+		seen[declaration.name.Lexeme] = true
+		kind := CompletionVariable
+		if declaration.function {
+			kind = CompletionFunction
+		}
+		items = append(items, CompletionItem{Label: declaration.name.Lexeme, Kind: kind, Detail: declaration.detail})
+	}
+	for _, native := range natives {
+		if !seen[native.name] {
+			items = append(items, CompletionItem{Label: native.name, Kind: CompletionFunction, Detail: native.detail})
+		}
+	}
+	return items
+}
+
+func (d *document) definition(position Position) *Location {
+	_, declaration, ok := d.lookup(d.offset(position))
+	if !ok || declaration == nil {
+		return nil
+	}
+	uri := d.uri
+	if declaration.name.File != d.path {
+		uri = pathToURI(declaration.name.File)
+	}
+	return &Location{URI: uri, Range: tokenRange(declaration.name)}
+}
+
+func (d *document) hover(position Position, natives []native) *Hover {
+	tok, declaration, ok := d.lookup(d.offset(position))
+	if !ok {
+		return nil
+	}
+	detail := ""
+	if declaration != nil {
+		detail = declaration.detail
+	} else {
+		for _, native := range natives {
+			if native.name == tok.Lexeme {
+				detail = native.detail
+			}
+		}
+	}
+	if detail == "" {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```ofin\n" + detail + "\n```"},
+		Range:    tokenRange(tok),
+	}
+}
+
+// symbols lists the scenarios of the document. A scenario covers the block
+// that follows it.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for i, statement := range d.statements {
+		scenario, ok := statement.(*ast.Scenario)
+		if !ok {
+			continue
+		}
+		span := scenario.Span()
+		if i+1 < len(d.statements) {
+			if block, ok := d.statements[i+1].(*ast.Block); ok {
+				span = span.To(block.Span())
+			}
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           scenario.Label,
+			Kind:           SymbolEvent,
+			Range:          spanRange(span),
+			SelectionRange: spanRange(scenario.Span()),
+		})
+	}
+	return symbols
+}
+
+func tokenRange(tok token.Token) Range {
+	return Range{
+		Start: Position{Line: tok.Line - 1, Character: tok.Column - 1},
+		End:   Position{Line: tok.Line - 1, Character: tok.EndColumn - 1},
+	}
+}
+
+func spanRange(span token.Span) Range {
+	return Range{
+		Start: Position{Line: span.Start.Line - 1, Character: span.Start.Column - 1},
+		End:   Position{Line: span.End.Line - 1, Character: span.End.Column - 1},
+	}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// readFile reads a script from the open documents, or from disk when it is
+// not open.
+func readFile(documents map[string]*document) func(path string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		for _, d := range documents {
+			if d.path == filepath.Clean(path) {
+				return []byte(d.text), nil
+			}
+		}
+		return os.ReadFile(path)
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,190 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/token"
+)
+
+// declaration is a name a script introduces: a variable given by Given or
+// And, a function, a parameter or a loop variable.
+type declaration struct {
+	name token.Token
+	// detail describes the declaration when hovering over one of its uses.
+	detail   string
+	function bool
+	// from and to are the offsets in the document between which the name can
+	// be used.
+	from int
+	to   int
+}
+
+// scopes collects the declarations of a document along with the stretch of
+// it each is visible in. Only statements declare names, so it does not visit
+// expressions.
+type scopes struct {
+	// source is the text of a script, the document or one it imports.
+	source       func(file string) string
+	declarations []declaration
+	// ends holds, for each scope being visited, the offset it ends at.
+	ends     []int
+	imported map[string]bool
+}
+
+func newScopes(source func(file string) string, end int) *scopes {
+	return &scopes{
+		source:   source,
+		ends:     []int{end},
+		imported: map[string]bool{},
+	}
+}
+
+func (s *scopes) collect(statements []ast.Statement) []declaration {
+	for _, statement := range statements {
+		s.visit(statement)
+	}
+	return s.declarations
+}
+
+func (s *scopes) visit(statement ast.Statement) {
+	if statement != nil {
+		statement.Accept(s)
+	}
+}
+
+func (s *scopes) visitInScope(end int, visit func()) {
+	s.ends = append(s.ends, end)
+	visit()
+	s.ends = s.ends[:len(s.ends)-1]
+}
+
+func (s *scopes) declare(name token.Token, detail string, function bool) {
+	s.declarations = append(s.declarations, declaration{
+		name:     name,
+		detail:   detail,
+		function: function,
+		from:     name.Start,
+		to:       s.ends[len(s.ends)-1],
+	})
+}
+
+// text is the source of a node.
+func (s *scopes) text(span token.Span) string {
+	source := s.source(span.File)
+	if span.Start.Offset < 0 || span.End.Offset > len(source) || span.Start.Offset > span.End.Offset {
+		return ""
+	}
+	return source[span.Start.Offset:span.End.Offset]
+}
+
+func (s *scopes) varDetail(statement *ast.Var) string {
+	if statement.Initializer == nil {
+		return statement.Keyword.Lexeme + " " + statement.Name.Lexeme
+	}
+	return fmt.Sprintf("%s %s = %s", statement.Keyword.Lexeme, statement.Name.Lexeme, s.text(statement.Initializer.Span()))
+}
+
+func (s *scopes) functionDetail(statement *ast.Function) string {
+	var params []string
+	for i, param := range statement.Params {
+		if statement.Defaults[i] != nil {
+			params = append(params, fmt.Sprintf("%s = %s", param.Lexeme, s.text(statement.Defaults[i].Span())))
+			continue
+		}
+		params = append(params, param.Lexeme)
+	}
+	return fmt.Sprintf("fn %s(%s)", statement.Name.Lexeme, strings.Join(params, ", "))
+}
+
+// importModule declares what a module defines at its top level, and what the
+// modules it imports define, from the end of the import onwards.
+func (s *scopes) importModule(module *ast.Module, from int) {
+	if module == nil || s.imported[module.File] {
+		return
+	}
+	s.imported[module.File] = true
+	to := s.ends[len(s.ends)-1]
+	for _, statement := range module.Statements {
+		switch statement := statement.(type) {
+		case *ast.Var:
+			s.declarations = append(s.declarations, declaration{name: statement.Name, detail: s.varDetail(statement), from: from, to: to})
+		case *ast.Function:
+			s.declarations = append(s.declarations, declaration{name: statement.Name, detail: s.functionDetail(statement), function: true, from: from, to: to})
+		case *ast.Import:
+			s.importModule(statement.Module, from)
+		}
+	}
+}
+
+func (s *scopes) VisitStmtExpressionStatement(statement *ast.StmtExpression) interface{} {
+	return nil
+}
+func (s *scopes) VisitIfStatement(statement *ast.If) interface{} {
+	s.visit(statement.ThenBranch)
+	s.visit(statement.ElseBranch)
+	return nil
+}
+func (s *scopes) VisitPrintStatement(statement *ast.Print) interface{} {
+	return nil
+}
+func (s *scopes) VisitWhenStatement(statement *ast.When) interface{} {
+	s.visit(statement.Body)
+	return nil
+}
+func (s *scopes) VisitThenStatement(statement *ast.Then) interface{} {
+	s.visit(statement.Body)
+	return nil
+}
+func (s *scopes) VisitAndStatement(statement *ast.And) interface{} {
+	return nil
+}
+func (s *scopes) VisitScenarioStatement(statement *ast.Scenario) interface{} {
+	return nil
+}
+func (s *scopes) VisitVarStatement(statement *ast.Var) interface{} {
+	s.declare(statement.Name, s.varDetail(statement), false)
+	return nil
+}
+func (s *scopes) VisitWhileStatement(statement *ast.While) interface{} {
+	s.visit(statement.Body)
+	return nil
+}
+func (s *scopes) VisitBlockStatement(statement *ast.Block) interface{} {
+	s.visitInScope(statement.Span().End.Offset, func() {
+		for _, inner := range statement.Statements {
+			s.visit(inner)
+		}
+	})
+	return nil
+}
+func (s *scopes) VisitDoNotingStatement(statement *ast.DoNoting) interface{} {
+	return nil
+}
+func (s *scopes) VisitFunctionStatement(statement *ast.Function) interface{} {
+	s.declare(statement.Name, s.functionDetail(statement), true)
+	s.visitInScope(statement.Span().End.Offset, func() {
+		for _, param := range statement.Params {
+			s.declare(param, fmt.Sprintf("parameter %s of fn %s", param.Lexeme, statement.Name.Lexeme), false)
+		}
+		for _, inner := range statement.Body {
+			s.visit(inner)
+		}
+	})
+	return nil
+}
+func (s *scopes) VisitReturnStatement(statement *ast.Return) interface{} {
+	return nil
+}
+func (s *scopes) VisitForStatement(statement *ast.For) interface{} {
+	s.visitInScope(statement.Span().End.Offset, func() {
+		s.declare(statement.Name, fmt.Sprintf("for %s in %s", statement.Name.Lexeme, s.text(statement.Iterable.Span())), false)
+		s.visit(statement.Body)
+	})
+	return nil
+}
+func (s *scopes) VisitImportStatement(statement *ast.Import) interface{} {
+	s.importModule(statement.Module, statement.Span().End.Offset)
+	return nil
+}
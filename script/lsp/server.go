@@ -0,0 +1,201 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// Server is a language server for ofin scripts. It speaks JSON-RPC over a
+// pair of streams, usually the standard input and output of the process.
+type Server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+	natives   []native
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: map[string]*document{},
+		natives:   natives(),
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input.
+func (s *Server) Run() error {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.write(errorResponse{JSONRPC: "2.0", Error: responseError{Code: parseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, failure := s.handle(&req)
+		if req.ID == nil {
+			continue
+		}
+		if failure != nil {
+			err = s.write(errorResponse{JSONRPC: "2.0", ID: req.ID, Error: *failure})
+		} else {
+			err = s.write(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// read reads the body of the next message.
+func (s *Server) read() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) handle(req *request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       1,
+				CompletionProvider:     &CompletionOptions{},
+				DefinitionProvider:     true,
+				HoverProvider:          true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "ofin"},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if failure := decode(req.Params, &params); failure != nil {
+			return nil, failure
+		}
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if failure := decode(req.Params, &params); failure != nil {
+			return nil, failure
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if failure := decode(req.Params, &params); failure != nil {
+			return nil, failure
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publish(params.TextDocument.URI, []Diagnostic{})
+	case "textDocument/completion":
+		return s.withDocument(req.Params, func(d *document, position Position) interface{} {
+			return d.completion(position, s.natives)
+		})
+	case "textDocument/definition":
+		return s.withDocument(req.Params, func(d *document, position Position) interface{} {
+			return d.definition(position)
+		})
+	case "textDocument/hover":
+		return s.withDocument(req.Params, func(d *document, position Position) interface{} {
+			return d.hover(position, s.natives)
+		})
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if failure := decode(req.Params, &params); failure != nil {
+			return nil, failure
+		}
+		d, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return []DocumentSymbol{}, nil
+		}
+		return d.symbols(), nil
+	}
+	if req.ID == nil {
+		// Notifications the server does not handle are dropped.
+		return nil, nil
+	}
+	return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf("method %q is not supported", req.Method)}
+}
+
+// open analyses the text of a document and publishes its diagnostics.
+func (s *Server) open(uri string, text string) *responseError {
+	d := analyze(uri, text, readFile(s.documents))
+	s.documents[uri] = d
+	diagnostics := d.diagnostics
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return s.publish(uri, diagnostics)
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) *responseError {
+	err := s.write(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+	if err != nil {
+		return &responseError{Code: parseError, Message: err.Error()}
+	}
+	return nil
+}
+
+// withDocument answers a request about a position in an open document. The
+// answer is null when the document is not open.
+func (s *Server) withDocument(raw json.RawMessage, answer func(d *document, position Position) interface{}) (interface{}, *responseError) {
+	var params TextDocumentPositionParams
+	if failure := decode(raw, &params); failure != nil {
+		return nil, failure
+	}
+	d, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return answer(d, params.Position), nil
+}
+
+func decode(raw json.RawMessage, params interface{}) *responseError {
+	if err := json.Unmarshal(raw, params); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
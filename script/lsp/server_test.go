@@ -0,0 +1,206 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///work/lsp-test.ac"
+
+const script = `fn double(x):
+    return x * 2
+Scenario "doubling":
+    Given a = 21
+    And b = double(a)
+    Then b == 42
+Scenario "broken":
+    Given c = (1
+`
+
+// exchange sends each request to a server and returns everything it wrote,
+// keyed by request id; notifications are keyed by method.
+func exchange(t *testing.T, requests ...string) map[string]json.RawMessage {
+	t.Helper()
+	var in bytes.Buffer
+	for _, req := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(req), req)
+	}
+	var out bytes.Buffer
+	if err := NewServer(&in, &out).Run(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	messages := map[string]json.RawMessage{}
+	reader := bufio.NewReader(&out)
+	for {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		var msg struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		switch {
+		case msg.ID == nil:
+			messages[msg.Method] = msg.Params
+		case msg.Error != nil:
+			messages[strconv.Itoa(*msg.ID)] = msg.Error
+		default:
+			messages[strconv.Itoa(*msg.ID)] = msg.Result
+		}
+	}
+}
+
+func open(text string) string {
+	params, _ := json.Marshal(DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, Text: text}})
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":%s}`, params)
+}
+
+func at(id int, method string, line int, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"textDocument/%s","params":{"textDocument":{"uri":%q},"position":{"line":%d,"character":%d}}}`,
+		id, method, uri, line, character)
+}
+
+func TestInitialize(t *testing.T) {
+	messages := exchange(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"bogus"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"shutdown"}`,
+	)
+	var result InitializeResult
+	json.Unmarshal(messages["1"], &result)
+	if !result.Capabilities.HoverProvider || !result.Capabilities.DefinitionProvider || result.Capabilities.CompletionProvider == nil {
+		t.Fatalf("capabilities wrong. got=%s", messages["1"])
+	}
+	if !strings.Contains(string(messages["2"]), "-32601") {
+		t.Fatalf("error wrong. expected method not found, got=%s", messages["2"])
+	}
+	if string(messages["3"]) != "null" {
+		t.Fatalf("shutdown wrong. expected=null, got=%s", messages["3"])
+	}
+	if _, ok := messages["4"]; ok {
+		t.Fatalf("request after exit was answered")
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	messages := exchange(t, open(script))
+	var params PublishDiagnosticsParams
+	json.Unmarshal(messages["textDocument/publishDiagnostics"], &params)
+	if params.URI != uri || len(params.Diagnostics) != 1 {
+		t.Fatalf("diagnostics wrong. got=%s", messages["textDocument/publishDiagnostics"])
+	}
+	diagnostic := params.Diagnostics[0]
+	if diagnostic.Range.Start.Line != 7 || !strings.Contains(diagnostic.Message, "Expect ')'") {
+		t.Fatalf("diagnostic wrong. got=%+v", diagnostic)
+	}
+}
+
+func TestDefinitionAndHover(t *testing.T) {
+	messages := exchange(t, open(script),
+		at(1, "definition", 4, 20),
+		at(2, "definition", 4, 13),
+		at(3, "hover", 5, 9),
+		at(4, "hover", 4, 20),
+		at(5, "hover", 1, 11),
+		at(6, "definition", 0, 0),
+	)
+	tests := []struct {
+		id       string
+		expected interface{}
+	}{
+		// a in double(a) is declared by Given on line 4.
+		{"1", Location{URI: uri, Range: Range{Start: Position{3, 10}, End: Position{3, 11}}}},
+		{"2", Location{URI: uri, Range: Range{Start: Position{0, 3}, End: Position{0, 9}}}},
+		{"3", "```ofin\nAnd b = double(a)\n```"},
+		{"4", "```ofin\nGiven a = 21\n```"},
+		{"5", "```ofin\nparameter x of fn double\n```"},
+	}
+	for _, tt := range tests {
+		switch expected := tt.expected.(type) {
+		case Location:
+			var location Location
+			json.Unmarshal(messages[tt.id], &location)
+			if location != expected {
+				t.Fatalf("request %s - location wrong. expected=%+v, got=%s", tt.id, expected, messages[tt.id])
+			}
+		case string:
+			var hover Hover
+			json.Unmarshal(messages[tt.id], &hover)
+			if hover.Contents.Value != expected {
+				t.Fatalf("request %s - hover wrong. expected=%q, got=%s", tt.id, expected, messages[tt.id])
+			}
+		}
+	}
+	if string(messages["6"]) != "null" {
+		t.Fatalf("definition wrong. expected=null, got=%s", messages["6"])
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	messages := exchange(t, open(script), at(1, "completion", 5, 9), at(2, "completion", 1, 4))
+	tests := []struct {
+		id       string
+		present  []string
+		excluded []string
+	}{
+		{"1", []string{"Scenario", "Given", "When", "Then", "And", "a", "b", "double", "clock", "len", "range"}, []string{"x", "c"}},
+		{"2", []string{"x", "double", "clock"}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		var items []CompletionItem
+		json.Unmarshal(messages[tt.id], &items)
+		labels := map[string]bool{}
+		for _, item := range items {
+			labels[item.Label] = true
+		}
+		for _, label := range tt.present {
+			if !labels[label] {
+				t.Fatalf("request %s - completion wrong. expected %q in %s", tt.id, label, messages[tt.id])
+			}
+		}
+		for _, label := range tt.excluded {
+			if labels[label] {
+				t.Fatalf("request %s - completion wrong. did not expect %q in %s", tt.id, label, messages[tt.id])
+			}
+		}
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	messages := exchange(t, open(script),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":%q}}}`, uri))
+	var symbols []DocumentSymbol
+	json.Unmarshal(messages["1"], &symbols)
+	if len(symbols) != 2 {
+		t.Fatalf("symbols wrong. got=%s", messages["1"])
+	}
+	if symbols[0].Name != "doubling" || symbols[0].Range.Start.Line != 2 || symbols[0].Range.End.Line != 5 {
+		t.Fatalf("symbol wrong. expected doubling on lines 2-5, got=%+v", symbols[0])
+	}
+	if symbols[1].Name != "broken" {
+		t.Fatalf("symbol wrong. expected=%q, got=%q", "broken", symbols[1].Name)
+	}
+}
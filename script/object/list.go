@@ -0,0 +1,48 @@
+package object
+
+import "strings"
+
+// List is the runtime value of a `[a, b, c]` literal.
+type List struct {
+	Elements []interface{}
+}
+
+func NewList(elements []interface{}) *List {
+	return &List{
+		Elements: elements,
+	}
+}
+
+func (l *List) Len() int {
+	return len(l.Elements)
+}
+
+func (l *List) Iterator() Iterator {
+	return &listIterator{l: l}
+}
+
+func (l *List) String() string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	for i, element := range l.Elements {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(Inspect(element))
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+type listIterator struct {
+	l     *List
+	index int
+}
+
+func (i *listIterator) Next() (interface{}, bool) {
+	if i.index >= len(i.l.Elements) {
+		return nil, false
+	}
+	i.index += 1
+	return i.l.Elements[i.index-1], true
+}
@@ -0,0 +1,75 @@
+package object
+
+import "strings"
+
+// Map is the runtime value of a `{k: v}` literal. Keys keep the order they
+// were first inserted in so maps print and iterate deterministically.
+type Map struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+func NewMap() *Map {
+	return &Map{
+		values: map[interface{}]interface{}{},
+	}
+}
+
+// IsHashable reports whether key can be used as a map key.
+func IsHashable(key interface{}) bool {
+	switch key.(type) {
+	case nil, bool, float64, string:
+		return true
+	}
+	return false
+}
+
+func (m *Map) Get(key interface{}) (interface{}, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *Map) Set(key interface{}, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Property exposes string keys as `m.key`.
+func (m *Map) Property(name string) (interface{}, bool) {
+	return m.Get(name)
+}
+
+func (m *Map) SetProperty(name string, value interface{}) error {
+	m.Set(name, value)
+	return nil
+}
+
+func (m *Map) Keys() []interface{} {
+	return append([]interface{}{}, m.keys...)
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Iterator walks the keys of the map in insertion order.
+func (m *Map) Iterator() Iterator {
+	return NewList(m.Keys()).Iterator()
+}
+
+func (m *Map) String() string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for i, key := range m.keys {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(Inspect(key))
+		builder.WriteString(": ")
+		builder.WriteString(Inspect(m.values[key]))
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
@@ -0,0 +1,108 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Iterator hands out the values of an Iterable one at a time; the second
+// return value is false once the values are exhausted.
+type Iterator interface {
+	Next() (interface{}, bool)
+}
+
+// Iterable is implemented by every value that can follow `in` in a for loop.
+type Iterable interface {
+	Iterator() Iterator
+}
+
+type stringIterator struct {
+	runes []rune
+	index int
+}
+
+// NewStringIterator iterates over the characters of s, each as a string.
+func NewStringIterator(s string) Iterator {
+	return &stringIterator{runes: []rune(s)}
+}
+
+func (s *stringIterator) Next() (interface{}, bool) {
+	if s.index >= len(s.runes) {
+		return nil, false
+	}
+	s.index += 1
+	return string(s.runes[s.index-1]), true
+}
+
+// Sized is implemented by values that the len native accepts.
+type Sized interface {
+	Len() int
+}
+
+// Inspect formats a value the way it is written in a script, so strings
+// nested in lists and maps are quoted.
+func Inspect(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// Instance is implemented by values that expose properties to scripts
+// through `value.name`. Embedders can implement it on their own Go types.
+type Instance interface {
+	Property(name string) (interface{}, bool)
+}
+
+// PropertySetter is implemented by Instances that allow `value.name = v`.
+type PropertySetter interface {
+	SetProperty(name string, value interface{}) error
+}
+
+// Equal compares two values, descending into lists and maps.
+func Equal(a interface{}, b interface{}) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *List:
+		y, ok := b.(*List)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+		for i := range x.Elements {
+			if !Equal(x.Elements[i], y.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		y, ok := b.(*Map)
+		if !ok || x.Len() != y.Len() {
+			return false
+		}
+		for _, key := range x.keys {
+			yv, ok := y.values[key]
+			if !ok || !Equal(x.values[key], yv) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Values a native hands back may be of any Go type; == panics on those
+	// that are not comparable, such as slices and funcs.
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
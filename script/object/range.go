@@ -0,0 +1,55 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Range is the lazy sequence of numbers produced by the range native.
+type Range struct {
+	Start float64
+	Stop  float64
+	Step  float64
+}
+
+func NewRange(start float64, stop float64, step float64) *Range {
+	return &Range{
+		Start: start,
+		Stop:  stop,
+		Step:  step,
+	}
+}
+
+// Len is the number of values the range yields.
+func (r *Range) Len() int {
+	if r.Step == 0 {
+		return 0
+	}
+	n := math.Ceil((r.Stop - r.Start) / r.Step)
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+func (r *Range) Iterator() Iterator {
+	return &rangeIterator{r: r}
+}
+
+func (r *Range) String() string {
+	return fmt.Sprintf("range(%v, %v, %v)", r.Start, r.Stop, r.Step)
+}
+
+type rangeIterator struct {
+	r     *Range
+	index int
+}
+
+func (i *rangeIterator) Next() (interface{}, bool) {
+	if i.index >= i.r.Len() {
+		return nil, false
+	}
+	value := i.r.Start + float64(i.index)*i.r.Step
+	i.index += 1
+	return value, true
+}
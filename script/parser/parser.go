@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/itsert/ofin/merror"
@@ -23,79 +22,120 @@ type Parser struct {
 	fileName     string
 	programState *environment.ProgramState
 	hasError     bool
+	// errors are every syntax error found by the lexer and the parser.
+	errors        merror.ErrorList
+	functionDepth int
+	// blockDepth counts the indented blocks being parsed.
+	blockDepth int
 }
 
 func NewParser(l *lexer.Lexer) *Parser {
+	return NewParserInState(l, environment.NewState())
+}
+
+// NewParserInState is NewParser for input that continues a program already
+// in state, such as each entry of the REPL.
+func NewParserInState(l *lexer.Lexer, state *environment.ProgramState) *Parser {
+	tokens, err := l.Tokenize()
+	var errors merror.ErrorList
+	if err != nil {
+		errors = err.(merror.ErrorList)
+	}
 	p := &Parser{
 		l:            l,
 		current:      0,
-		tokens:       l.Tokenize(),
+		tokens:       tokens,
 		fileName:     l.File,
-		programState: environment.NewState(),
-		hasError:     false,
+		programState: state,
+		hasError:     err != nil,
+		errors:       errors,
 	}
 	return p
 }
 
-func (p *Parser) ParseProgram() (stmnts []ast.Statement, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New("error  encountered")
-			fmt.Println("Recovered in f", r)
-		}
-	}()
+// ParseProgram parses the whole script. A declaration that does not parse
+// is left out and parsing resumes at the next one, so the statements are a
+// partial AST whenever the error, a merror.ErrorList of every problem found
+// while lexing or parsing, is not nil.
+func (p *Parser) ParseProgram() ([]ast.Statement, error) {
 	var statements []ast.Statement
 	for !p.end() {
-		decl, _ := p.declaration()
-		statements = append(statements, decl)
+		if decl, err := p.declaration(); err == nil {
+			statements = append(statements, decl)
+		}
 	}
-	return statements, err
+	p.errors.Sort()
+	return statements, p.errors.Err()
 }
 
 func (p *Parser) declaration() (stmnt ast.Statement, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = errors.New("error  encountered")
-			fmt.Println("Recovered in f", r)
+			syntaxError, ok := r.(*merror.SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = syntaxError
+			p.errors = append(p.errors, syntaxError)
 			p.synchronize()
 			p.hasError = true
 		}
 	}()
+	start := p.current
 	if p.lookAhead(token.GIVEN) {
-		p.programState.Transition(environment.GIVEN)
-		return p.varDeclaration(), err
+		if !p.inFunction() {
+			p.programState.Transition(environment.GIVEN)
+		}
+		return p.statementFrom(start, p.varDeclaration()), err
 	}
-	return p.actionStatements(), err
+	return p.statementFrom(start, p.actionStatements()), err
 }
 
 func (p *Parser) varDeclaration() ast.Statement {
+	start := p.current - 1
+	keyword := p.previous()
 	name := p.consume("Expecting a variable name", token.IDENTIFIER)
 
 	var initializer ast.Expression = nil
 	if p.lookAhead(token.ASSIGN) {
 		initializer = p.expression()
 	}
-	p.consume("", token.NEWLINE, token.EOF)
-	return ast.NewVar(name, initializer)
+	if !p.end() {
+		p.consume(fmt.Sprintf(EofNewlineMsg, keyword.Lexeme), token.NEWLINE)
+	}
+	return p.statementFrom(start, ast.NewVar(keyword, name, initializer))
 }
 
 func (p *Parser) nonActionStatements() ast.Statement {
+	start := p.current
 	if p.lookAhead(token.NEWLINE, token.EOF) {
-		return ast.NewDoNoting(p.peek())
+		return p.statementFrom(start, ast.NewDoNoting(p.peek()))
 	}
 	if p.lookAhead(token.IF) {
-		return p.ifStatement()
+		return p.statementFrom(start, p.ifStatement())
 	}
 	if p.lookAhead(token.PRINT) {
-		return p.printStatement()
+		return p.statementFrom(start, p.printStatement())
 	}
 	if p.lookAhead(token.WHILE) {
-		return p.whileStatement()
+		return p.statementFrom(start, p.whileStatement())
+	}
+	if p.lookAhead(token.FOR) {
+		return p.statementFrom(start, p.forStatement())
+	}
+	if p.lookAhead(token.FUNCTION) {
+		return p.statementFrom(start, p.function())
+	}
+	if p.lookAhead(token.RETURN) {
+		return p.statementFrom(start, p.returnStatement())
+	}
+	if p.lookAhead(token.IMPORT) {
+		return p.statementFrom(start, p.importStatement())
 	}
 	if p.lookAhead(token.INDENT) {
-		return ast.NewBlock(p.block(), p.programState.CurrentState())
+		return p.statementFrom(start, ast.NewBlock(p.block(), p.programState.CurrentState()))
 	}
-	return p.expressionStatement()
+	return p.statementFrom(start, p.expressionStatement())
 }
 
 func (p *Parser) consumeBlockStart(name string) {
@@ -107,9 +147,9 @@ func (p *Parser) ifStatement() ast.Statement {
 	p.consumeBlockStart("if")
 	var elseBranch ast.Statement = nil
 	var thenBranch ast.Statement = nil
-	if p.programState.IsState(environment.GLOBAL) {
-		merror.Error(p.fileName, p.peek().Line, p.peek().Line, "conditional not expected in global context")
-	} else if p.programState.IsState(environment.SCENARIO) {
+	if p.programState.IsState(environment.GLOBAL) && !p.inFunction() {
+		merror.TokenError(p.fileName, p.peek(), "conditional not expected in global context")
+	} else if p.programState.IsState(environment.SCENARIO) && !p.inFunction() {
 		thenBranch = p.actionStatements()
 		if p.lookAhead(token.ELSE) {
 			p.consumeBlockStart("else")
@@ -126,48 +166,57 @@ func (p *Parser) ifStatement() ast.Statement {
 }
 
 func (p *Parser) actionStatements() ast.Statement {
+	start := p.current
 	if p.lookAhead(token.AND) {
-		return p.andStatement()
+		return p.statementFrom(start, p.andStatement())
 	}
 
 	if p.lookAhead(token.WHEN) {
-		return p.whenStatement()
+		return p.statementFrom(start, p.whenStatement())
 	}
 
 	if p.lookAhead(token.THEN) {
-		return p.thenStatement()
+		return p.statementFrom(start, p.thenStatement())
 	}
 
 	if p.lookAhead(token.SCENARIO) {
-		return p.scenarioStatement()
+		return p.statementFrom(start, p.scenarioStatement())
 	}
 
-	return p.nonActionStatements()
+	return p.statementFrom(start, p.nonActionStatements())
 }
 
 func (p *Parser) block() []ast.Statement {
+	p.blockDepth += 1
+	defer func() {
+		p.blockDepth -= 1
+	}()
 	var statements []ast.Statement
 	for !p.lookAhead(token.DEDENT) && !p.end() {
-		declaration, err := p.declaration()
-		_ = err
-		statements = append(statements, declaration)
+		if declaration, err := p.declaration(); err == nil {
+			statements = append(statements, declaration)
+		}
 	}
 
 	if p.peek().Type != token.EOF && p.previous().Type != token.DEDENT {
-		merror.Error(p.fileName, p.previous().Line, p.previous().Line, "Expects a dedenatation or EOF after block.")
+		merror.TokenError(p.fileName, p.previous(), "Expects a dedenatation or EOF after block.")
 	}
 
 	return statements
 }
 
 func (p *Parser) subBlock() []ast.Statement {
+	p.blockDepth += 1
+	defer func() {
+		p.blockDepth -= 1
+	}()
 	var statements []ast.Statement
 	for !p.lookAhead(token.DEDENT) && !p.end() {
 		statements = append(statements, p.nonActionStatements())
 	}
 
 	if p.peek().Type != token.EOF && p.previous().Type != token.DEDENT {
-		merror.Error(p.fileName, p.previous().Line, p.previous().Line, "Expects a dedenatation or EOF after block.")
+		merror.TokenError(p.fileName, p.previous(), "Expects a dedenatation or EOF after block.")
 	}
 
 	return statements
@@ -183,6 +232,7 @@ func (p *Parser) printStatement() ast.Statement {
 }
 
 func (p *Parser) andStatement() ast.Statement {
+	keyword := p.previous()
 	if p.programState.IsState(environment.GIVEN) {
 		return p.varDeclaration()
 	} else {
@@ -190,50 +240,146 @@ func (p *Parser) andStatement() ast.Statement {
 		if !p.end() {
 			p.consume(fmt.Sprintf(EofNewlineMsg, "And"), token.NEWLINE)
 		}
-		return ast.NewAnd(value)
+		return ast.NewAnd(keyword, value)
 	}
 }
 func (p *Parser) whenStatement() ast.Statement {
+	keyword := p.previous()
 	p.programState.Transition(environment.WHEN)
 	if p.lookAhead(token.COLON) {
 		p.consume(fmt.Sprintf(StmtStartErrorMsg, "When"), token.NEWLINE)
 		p.consume(fmt.Sprintf(StmtStartErrorMsg, "When"), token.INDENT)
-		return ast.NewBlock(p.subBlock(), p.programState.CurrentState())
+		start := p.current
+		body := ast.NewBlock(p.subBlock(), p.programState.CurrentState())
+		return ast.NewWhen(keyword, nil, p.statementFrom(start, body))
 	} else {
 		value := p.expression()
 		if !p.end() {
 			p.consume(fmt.Sprintf(EofNewlineMsg, "When"), token.NEWLINE)
 		}
-		return ast.NewWhen(value)
+		return ast.NewWhen(keyword, value, nil)
 	}
 }
 
 func (p *Parser) thenStatement() ast.Statement {
+	keyword := p.previous()
 	p.programState.Transition(environment.THEN)
 	if p.lookAhead(token.COLON) {
 		p.consume(fmt.Sprintf(StmtStartErrorMsg, "Then"), token.NEWLINE)
 		p.consume(fmt.Sprintf(StmtStartErrorMsg, "Then"), token.INDENT)
-		return ast.NewBlock(p.subBlock(), p.programState.CurrentState())
+		start := p.current
+		body := ast.NewBlock(p.subBlock(), p.programState.CurrentState())
+		return ast.NewThen(keyword, nil, p.statementFrom(start, body))
 	} else {
 		value := p.expression()
 		if !p.end() {
 			p.consume(fmt.Sprintf(EofNewlineMsg, "When"), token.NEWLINE)
 		}
-		return ast.NewThen(value)
+		return ast.NewThen(keyword, value, nil)
 	}
 }
 
 func (p *Parser) scenarioStatement() ast.Statement {
+	keyword := p.previous()
 	var label string
 	p.programState.Transition(environment.SCENARIO)
 	if p.lookAhead(token.STRING) {
 		label = p.previous().Literal.(string)
 	} else {
-		merror.RuntimeError(p.peek(), "Expected string label")
+		merror.TokenError(p.fileName, p.peek(), "Expected string label")
 	}
 	p.consume("Expect COLON to indicate start of new block", token.COLON)
 	p.consume(fmt.Sprintf(EofNewlineMsg, "Scenario"), token.NEWLINE)
-	return ast.NewScenario(label)
+	return ast.NewScenario(keyword, label)
+}
+
+func (p *Parser) function() ast.Statement {
+	name := p.consume("Expect function name", token.IDENTIFIER)
+	p.consume("Expect '(' after function name", token.LEFT_PAREN)
+	var params []token.Token
+	// defaults holds the default value of each parameter, nil for those
+	// that must be passed.
+	var defaults []ast.Expression
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(params) >= MaxFunctionArguments {
+				merror.TokenError(p.fileName, p.peek(), fmt.Sprintf("Can't have more than %d parameters.", MaxFunctionArguments))
+			}
+			param := p.consume("Expect parameter name", token.IDENTIFIER)
+			var value ast.Expression
+			if p.lookAhead(token.ASSIGN) {
+				value = p.expression()
+			} else if len(defaults) > 0 && defaults[len(defaults)-1] != nil {
+				merror.TokenError(p.fileName, param, "Parameter without a default can't follow one with a default.")
+			}
+			params = append(params, param)
+			defaults = append(defaults, value)
+			if !p.lookAhead(token.COMMA) {
+				break
+			}
+		}
+	}
+	p.consume("Expect ')' after parameters", token.RIGHT_PAREN)
+	p.consumeBlockStart("fn")
+	p.consume(fmt.Sprintf(StmtStartErrorMsg, "fn"), token.INDENT)
+
+	p.functionDepth += 1
+	defer func() {
+		p.functionDepth -= 1
+	}()
+	return ast.NewFunction(name, params, defaults, p.functionBody())
+}
+
+// functionBody is a subBlock that also accepts Given, which declares a
+// variable local to the function.
+func (p *Parser) functionBody() []ast.Statement {
+	var statements []ast.Statement
+	for !p.lookAhead(token.DEDENT) && !p.end() {
+		if p.lookAhead(token.GIVEN) {
+			statements = append(statements, p.varDeclaration())
+		} else {
+			statements = append(statements, p.nonActionStatements())
+		}
+	}
+
+	if p.peek().Type != token.EOF && p.previous().Type != token.DEDENT {
+		merror.TokenError(p.fileName, p.previous(), "Expects a dedenatation or EOF after block.")
+	}
+
+	return statements
+}
+
+func (p *Parser) returnStatement() ast.Statement {
+	keyword := p.previous()
+	if !p.inFunction() {
+		merror.TokenError(p.fileName, keyword, "Can't return from top-level code")
+	}
+	var value ast.Expression = nil
+	if !p.check(token.NEWLINE) && !p.end() {
+		value = p.expression()
+	}
+	if !p.end() {
+		p.consume(fmt.Sprintf(EofNewlineMsg, "Return"), token.NEWLINE)
+	}
+	return ast.NewReturn(keyword, value)
+}
+
+// importStatement parses `import "path"`. The module it names is attached
+// later by the loader.
+func (p *Parser) importStatement() ast.Statement {
+	keyword := p.previous()
+	if p.blockDepth > 0 || p.inFunction() {
+		merror.TokenError(p.fileName, keyword, "import is only allowed at the top level of a script")
+	}
+	path := p.consume("Expect file name string after 'import'", token.STRING)
+	if !p.end() {
+		p.consume(fmt.Sprintf(EofNewlineMsg, "import"), token.NEWLINE)
+	}
+	return ast.NewImport(keyword, path, nil)
+}
+
+func (p *Parser) inFunction() bool {
+	return p.functionDepth > 0
 }
 
 func (p *Parser) expressionStatement() ast.Statement {
@@ -249,6 +395,7 @@ func (p *Parser) expression() ast.Expression {
 }
 
 func (p *Parser) assignment() ast.Expression {
+	start := p.current
 	expr := p.or()
 
 	if p.lookAhead(token.ASSIGN) {
@@ -256,114 +403,135 @@ func (p *Parser) assignment() ast.Expression {
 		value := p.assignment()
 		if field, ok := expr.(*ast.Variable); ok {
 			name := field.Name
-			return ast.NewAssign(name, value)
+			return p.expressionFrom(start, ast.NewAssign(name, value))
+		}
+		if index, ok := expr.(*ast.Index); ok {
+			return p.expressionFrom(start, ast.NewSetIndex(index.Object, index.Bracket, index.Key, value))
 		}
-		merror.RuntimeError(equals, "Invalid assignment target")
+		if get, ok := expr.(*ast.Get); ok {
+			return p.expressionFrom(start, ast.NewSet(get.Object, get.Name, value))
+		}
+		merror.TokenError(p.fileName, equals, "Invalid assignment target")
 	}
 	return expr
 }
 
 func (p *Parser) or() ast.Expression {
+	start := p.current
 	expr := p.and()
 
 	for p.lookAhead(token.LOGICAL_OR) {
 		operator := p.previous()
 		right := p.and()
-		expr = ast.NewLogical(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewLogical(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) and() ast.Expression {
+	start := p.current
 	expr := p.equality()
 
 	for p.lookAhead(token.LOGICAL_AND) {
 		operator := p.previous()
 		right := p.equality()
-		expr = ast.NewLogical(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewLogical(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) equality() ast.Expression {
+	start := p.current
 	expr := p.comparison()
 
 	for p.lookAhead(token.BANG_EQUAL, token.EQUAL) {
 		operator := p.previous()
 		right := p.comparison()
-		expr = ast.NewBinary(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewBinary(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) comparison() ast.Expression {
+	start := p.current
 	expr := p.addSub()
 
 	for p.lookAhead(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL) {
 		operator := p.previous()
 		right := p.addSub()
-		expr = ast.NewBinary(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewBinary(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) addSub() ast.Expression {
+	start := p.current
 	expr := p.factor()
 
 	for p.lookAhead(token.MINUS, token.PLUS) {
 		operator := p.previous()
 		right := p.factor()
-		expr = ast.NewBinary(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewBinary(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) factor() ast.Expression {
+	start := p.current
 	expr := p.unary()
 
 	for p.lookAhead(token.SLASH, token.ASTERISK) {
 		operator := p.previous()
 		right := p.unary()
-		expr = ast.NewBinary(expr, operator, right)
+		expr = p.expressionFrom(start, ast.NewBinary(expr, operator, right))
 	}
 	return expr
 }
 
 func (p *Parser) unary() ast.Expression {
-
+	start := p.current
 	if p.lookAhead(token.BANG, token.MINUS) {
 		operator := p.previous()
 		right := p.unary()
-		return ast.NewUnary(operator, right)
+		return p.expressionFrom(start, ast.NewUnary(operator, right))
 	}
 	return p.call()
 }
 
 func (p *Parser) primary() ast.Expression {
+	start := p.current
 	if p.lookAhead(token.FALSE) {
-		return ast.NewLiteral(false)
+		return p.expressionFrom(start, ast.NewLiteral(false))
 	}
 	if p.lookAhead(token.TRUE) {
-		return ast.NewLiteral(true)
+		return p.expressionFrom(start, ast.NewLiteral(true))
 	}
 	if p.lookAhead(token.NIL) {
-		return ast.NewLiteral(nil)
+		return p.expressionFrom(start, ast.NewLiteral(nil))
 	}
 
 	if p.lookAhead(token.NUMBER, token.STRING) {
-		return ast.NewLiteral(p.previous().Literal)
+		return p.expressionFrom(start, ast.NewLiteral(p.previous().Literal))
 	}
 
 	if p.lookAhead(token.IDENTIFIER) {
-		return ast.NewVariable(p.previous())
+		return p.expressionFrom(start, ast.NewVariable(p.previous()))
 	}
 
 	if p.lookAhead(token.LEFT_PAREN) {
 		expr := p.expression()
 		p.consume("Expect ')' after expression", token.RIGHT_PAREN)
-		return ast.NewGrouping(expr)
+		return p.expressionFrom(start, ast.NewGrouping(expr))
 	}
-	merror.Error(p.fileName, p.peek().Line, p.peek().Line, "Expected expression")
+
+	if p.lookAhead(token.LEFT_BRACKET) {
+		return p.expressionFrom(start, p.list())
+	}
+
+	if p.lookAhead(token.LEFT_BRACE) {
+		return p.expressionFrom(start, p.mapLiteral())
+	}
+	merror.TokenError(p.fileName, p.peek(), "Expected expression")
 	return nil
 }
 
@@ -373,10 +541,40 @@ func (p *Parser) consume(message string, types ...token.TokenType) token.Token {
 			return p.advance()
 		}
 	}
-	merror.Error(p.fileName, p.peek().Line, p.peek().Line, message)
+	merror.TokenError(p.fileName, p.peek(), message)
 	return token.Token{}
 }
 
+// spanFrom is the span of the tokens consumed since the one at index start,
+// leaving out line breaks and indentation around them.
+func (p *Parser) spanFrom(start int) token.Span {
+	first, last := start, p.current-1
+	for first < last && isLayout(p.tokens[first].Type) {
+		first += 1
+	}
+	for last > first && isLayout(p.tokens[last].Type) {
+		last -= 1
+	}
+	if last < first {
+		last = first
+	}
+	return p.tokens[first].Span().To(p.tokens[last].Span())
+}
+
+func isLayout(t token.TokenType) bool {
+	return t == token.NEWLINE || t == token.INDENT || t == token.DEDENT || t == token.EOF
+}
+
+func (p *Parser) expressionFrom(start int, expr ast.Expression) ast.Expression {
+	expr.SetSpan(p.spanFrom(start))
+	return expr
+}
+
+func (p *Parser) statementFrom(start int, stmt ast.Statement) ast.Statement {
+	stmt.SetSpan(p.spanFrom(start))
+	return stmt
+}
+
 func (p *Parser) synchronize() {
 	p.advance()
 	for !p.end() {
@@ -385,7 +583,7 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().Type {
-		case token.SCENARIO, token.FUNCTION, token.GIVEN, token.IF, token.WHILE, token.PRINT, token.RETURN:
+		case token.SCENARIO, token.FUNCTION, token.GIVEN, token.IF, token.WHILE, token.FOR, token.PRINT, token.RETURN, token.IMPORT:
 			return
 		}
 		p.advance()
@@ -438,9 +636,9 @@ func (p *Parser) whileStatement() ast.Statement {
 	condition := p.expression()
 	p.consumeBlockStart("while")
 	var body ast.Statement = nil
-	if p.programState.IsState(environment.GLOBAL) {
-		merror.Error(p.fileName, p.peek().Line, p.peek().Line, "loop not expected in global context")
-	} else if p.programState.IsState(environment.SCENARIO) {
+	if p.programState.IsState(environment.GLOBAL) && !p.inFunction() {
+		merror.TokenError(p.fileName, p.peek(), "loop not expected in global context")
+	} else if p.programState.IsState(environment.SCENARIO) && !p.inFunction() {
 		body = p.actionStatements()
 	} else {
 		body = p.nonActionStatements()
@@ -448,12 +646,37 @@ func (p *Parser) whileStatement() ast.Statement {
 	return ast.NewWhile(condition, body)
 }
 
+func (p *Parser) forStatement() ast.Statement {
+	name := p.consume("Expect loop variable name after 'for'", token.IDENTIFIER)
+	p.consume("Expect 'in' after loop variable", token.IN)
+	iterable := p.expression()
+	p.consumeBlockStart("for")
+	var body ast.Statement = nil
+	if p.programState.IsState(environment.GLOBAL) && !p.inFunction() {
+		merror.TokenError(p.fileName, p.peek(), "loop not expected in global context")
+	} else if p.programState.IsState(environment.SCENARIO) && !p.inFunction() {
+		body = p.actionStatements()
+	} else {
+		body = p.nonActionStatements()
+	}
+	return ast.NewFor(name, iterable, body)
+}
+
 func (p *Parser) call() ast.Expression {
+	start := p.current
 	expression := p.primary()
 
 	for {
 		if p.lookAhead(token.LEFT_PAREN) {
-			expression = p.finishCall(expression)
+			expression = p.expressionFrom(start, p.finishCall(expression))
+		} else if p.lookAhead(token.LEFT_BRACKET) {
+			bracket := p.previous()
+			key := p.expression()
+			p.consume("Expect ']' after index", token.RIGHT_BRACKET)
+			expression = p.expressionFrom(start, ast.NewIndex(expression, bracket, key))
+		} else if p.lookAhead(token.DOT) {
+			name := p.consume("Expect property name after '.'", token.IDENTIFIER)
+			expression = p.expressionFrom(start, ast.NewGet(expression, name))
 		} else {
 			break
 		}
@@ -466,10 +689,10 @@ func (p *Parser) finishCall(callee ast.Expression) ast.Expression {
 	if !p.check(token.RIGHT_PAREN) {
 		for {
 			if len(arguments) > MaxFunctionArguments {
-				merror.RuntimeError(p.peek(), fmt.Sprintf("Can't have more than %d arguments.", MaxFunctionArguments))
+				merror.TokenError(p.fileName, p.peek(), fmt.Sprintf("Can't have more than %d arguments.", MaxFunctionArguments))
 			}
 			arguments = append(arguments, p.expression())
-			for !p.lookAhead(token.COMMA) {
+			if !p.lookAhead(token.COMMA) {
 				break
 			}
 		}
@@ -478,3 +701,36 @@ func (p *Parser) finishCall(callee ast.Expression) ast.Expression {
 	paren := p.consume("Expected ')' after arguments.", token.RIGHT_PAREN)
 	return ast.NewCall(callee, paren, arguments)
 }
+
+func (p *Parser) list() ast.Expression {
+	bracket := p.previous()
+	var elements []ast.Expression
+	if !p.check(token.RIGHT_BRACKET) {
+		for {
+			elements = append(elements, p.expression())
+			if !p.lookAhead(token.COMMA) {
+				break
+			}
+		}
+	}
+	p.consume("Expect ']' after list elements", token.RIGHT_BRACKET)
+	return ast.NewList(bracket, elements)
+}
+
+func (p *Parser) mapLiteral() ast.Expression {
+	brace := p.previous()
+	var keys []ast.Expression
+	var values []ast.Expression
+	if !p.check(token.RIGHT_BRACE) {
+		for {
+			keys = append(keys, p.expression())
+			p.consume("Expect ':' after map key", token.COLON)
+			values = append(values, p.expression())
+			if !p.lookAhead(token.COMMA) {
+				break
+			}
+		}
+	}
+	p.consume("Expect '}' after map entries", token.RIGHT_BRACE)
+	return ast.NewMap(brace, keys, values)
+}
@@ -1 +1,293 @@
 package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/token"
+)
+
+func TestWithFunctionDeclaration(t *testing.T) {
+	input := `fn add(a, b):
+    if a > b:
+        return a
+    return a + b
+`
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(stmnts) != 1 {
+		t.Fatalf("Length unmatching. expected=%d, got=%d", 1, len(stmnts))
+	}
+
+	function, ok := stmnts[0].(*ast.Function)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.Function, got=%T", stmnts[0])
+	}
+	if function.Name.Lexeme != "add" {
+		t.Fatalf("name wrong. expected=%q, got=%q", "add", function.Name.Lexeme)
+	}
+	if len(function.Params) != 2 || function.Params[0].Lexeme != "a" || function.Params[1].Lexeme != "b" {
+		t.Fatalf("params wrong. got=%+v", function.Params)
+	}
+	if len(function.Body) != 2 {
+		t.Fatalf("body length wrong. expected=%d, got=%d", 2, len(function.Body))
+	}
+	if _, ok := function.Body[1].(*ast.Return); !ok {
+		t.Fatalf("body[1] wrong. expected=*ast.Return, got=%T", function.Body[1])
+	}
+}
+
+func TestWithDefaultParameters(t *testing.T) {
+	input := `fn greet(name, greeting = "hello", times = 1 + 1):
+    return greeting
+`
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	function := stmnts[0].(*ast.Function)
+	if len(function.Defaults) != 3 || function.Defaults[0] != nil {
+		t.Fatalf("defaults wrong. got=%+v", function.Defaults)
+	}
+	if literal, ok := function.Defaults[1].(*ast.Literal); !ok || literal.Value != "hello" {
+		t.Fatalf("defaults[1] wrong. expected=%q, got=%+v", "hello", function.Defaults[1])
+	}
+	if _, ok := function.Defaults[2].(*ast.Binary); !ok {
+		t.Fatalf("defaults[2] wrong. expected=*ast.Binary, got=%T", function.Defaults[2])
+	}
+
+	_, err = NewParser(lexer.NewLexer("fn f(a = 1, b):\n    return b\n", "parser-test.ac")).ParseProgram()
+	errors, ok := err.(merror.ErrorList)
+	if !ok || errors[0].Message != "Parameter without a default can't follow one with a default." || errors[0].Column != 13 {
+		t.Fatalf("error wrong. got=%v", err)
+	}
+}
+
+func TestWithCallArguments(t *testing.T) {
+	input := "Given a = add(1, 2, 3)\n"
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	call, ok := stmnts[0].(*ast.Var).Initializer.(*ast.Call)
+	if !ok {
+		t.Fatalf("initializer wrong. expected=*ast.Call, got=%T", stmnts[0].(*ast.Var).Initializer)
+	}
+	if len(call.Arguments) != 3 {
+		t.Fatalf("arguments wrong. expected=%d, got=%d", 3, len(call.Arguments))
+	}
+}
+
+func TestWithForStatement(t *testing.T) {
+	input := `Scenario "loop":
+    When:
+        for item in range(0, 3):
+            print item
+`
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The indented scenario body is a block and the When: sub-block is its body.
+	body, ok := stmnts[1].(*ast.Block)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.Block, got=%T", stmnts[1])
+	}
+	when, ok := body.Statements[0].(*ast.When)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.When, got=%T", body.Statements[0])
+	}
+	loop, ok := when.Body.(*ast.Block).Statements[0].(*ast.For)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.For, got=%T", when.Body.(*ast.Block).Statements[0])
+	}
+	if loop.Name.Lexeme != "item" {
+		t.Fatalf("loop variable wrong. expected=%q, got=%q", "item", loop.Name.Lexeme)
+	}
+	if _, ok := loop.Iterable.(*ast.Call); !ok {
+		t.Fatalf("iterable wrong. expected=*ast.Call, got=%T", loop.Iterable)
+	}
+}
+
+func TestWithListAndMapLiterals(t *testing.T) {
+	input := "Given a = {\"k\": [1, 2]}\nWhen a[\"k\"][0] = 3\n"
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	m, ok := stmnts[0].(*ast.Var).Initializer.(*ast.Map)
+	if !ok {
+		t.Fatalf("initializer wrong. expected=*ast.Map, got=%T", stmnts[0].(*ast.Var).Initializer)
+	}
+	if len(m.Keys) != 1 {
+		t.Fatalf("keys wrong. expected=%d, got=%d", 1, len(m.Keys))
+	}
+	if _, ok := m.Values[0].(*ast.List); !ok {
+		t.Fatalf("value wrong. expected=*ast.List, got=%T", m.Values[0])
+	}
+
+	when, ok := stmnts[1].(*ast.When)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.When, got=%T", stmnts[1])
+	}
+	set, ok := when.Expr.(*ast.SetIndex)
+	if !ok {
+		t.Fatalf("expression wrong. expected=*ast.SetIndex, got=%T", when.Expr)
+	}
+	if _, ok := set.Object.(*ast.Index); !ok {
+		t.Fatalf("target wrong. expected=*ast.Index, got=%T", set.Object)
+	}
+}
+
+func TestWithPropertyAccess(t *testing.T) {
+	input := "When resp.headers.type = s.upper()\n"
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	set, ok := stmnts[0].(*ast.When).Expr.(*ast.Set)
+	if !ok {
+		t.Fatalf("expression wrong. expected=*ast.Set, got=%T", stmnts[0].(*ast.When).Expr)
+	}
+	if set.Name.Lexeme != "type" {
+		t.Fatalf("name wrong. expected=%q, got=%q", "type", set.Name.Lexeme)
+	}
+	if _, ok := set.Object.(*ast.Get); !ok {
+		t.Fatalf("target wrong. expected=*ast.Get, got=%T", set.Object)
+	}
+	call, ok := set.Value.(*ast.Call)
+	if !ok {
+		t.Fatalf("value wrong. expected=*ast.Call, got=%T", set.Value)
+	}
+	if _, ok := call.Callee.(*ast.Get); !ok {
+		t.Fatalf("callee wrong. expected=*ast.Get, got=%T", call.Callee)
+	}
+}
+
+func TestWithSyntaxErrors(t *testing.T) {
+	input := `Given a = 1
+Given b = (a
+Given c = a +
+Given d = 4 $
+Given e = 5
+`
+	tests := []struct {
+		line    int
+		column  int
+		message string
+	}{
+		{2, 13, "Expect ')' after expression"},
+		{3, 14, "Expected expression"},
+		{4, 13, "unexpected character."},
+	}
+
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	errors, ok := err.(merror.ErrorList)
+	if !ok {
+		t.Fatalf("error wrong. expected=merror.ErrorList, got=%T", err)
+	}
+	if len(errors) != len(tests) {
+		t.Fatalf("Length unmatching. expected=%d, got=%d (%v)", len(tests), len(errors), errors)
+	}
+	for i, tt := range tests {
+		if errors[i].File != "parser-test.ac" || errors[i].Line != tt.line || errors[i].Column != tt.column {
+			t.Fatalf("tests[%d] - position wrong. expected=%s:%d:%d, got=%s:%d:%d",
+				i, "parser-test.ac", tt.line, tt.column, errors[i].File, errors[i].Line, errors[i].Column)
+		}
+		if errors[i].Message != tt.message {
+			t.Fatalf("tests[%d] - message wrong. expected=%q, got=%q", i, tt.message, errors[i].Message)
+		}
+	}
+	if errors[0].Token.Type != "NEWLINE" {
+		t.Fatalf("token wrong. expected=%q, got=%q", "NEWLINE", errors[0].Token.Type)
+	}
+
+	// The declarations that parsed are kept.
+	var names []string
+	for _, stmnt := range stmnts {
+		names = append(names, stmnt.(*ast.Var).Name.Lexeme)
+	}
+	if strings.Join(names, " ") != "a d e" {
+		t.Fatalf("statements wrong. expected=%q, got=%q", "a d e", strings.Join(names, " "))
+	}
+}
+
+func TestWithSpans(t *testing.T) {
+	input := `Scenario "spans":
+    Given a = [1, 2]
+    Then len(a) + 1 == 3
+`
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	block := stmnts[1].(*ast.Block)
+	given := block.Statements[0].(*ast.Var)
+	then := block.Statements[1].(*ast.Then)
+	binary := then.Expr.(*ast.Binary)
+
+	tests := []struct {
+		node      interface{ Span() token.Span }
+		text      string
+		line      int
+		column    int
+		endLine   int
+		endColumn int
+	}{
+		{stmnts[0], `Scenario "spans":`, 1, 1, 1, 18},
+		{block, "Given a = [1, 2]\n    Then len(a) + 1 == 3", 2, 5, 3, 25},
+		{given, "Given a = [1, 2]", 2, 5, 2, 21},
+		{given.Initializer, "[1, 2]", 2, 15, 2, 21},
+		{then, "Then len(a) + 1 == 3", 3, 5, 3, 25},
+		{binary, "len(a) + 1 == 3", 3, 10, 3, 25},
+		{binary.Left, "len(a) + 1", 3, 10, 3, 20},
+		{binary.Left.(*ast.Binary).Left, "len(a)", 3, 10, 3, 16},
+		{binary.Right, "3", 3, 24, 3, 25},
+	}
+	for i, tt := range tests {
+		span := tt.node.Span()
+		if span.File != "parser-test.ac" {
+			t.Fatalf("tests[%d] - file wrong. expected=%q, got=%q", i, "parser-test.ac", span.File)
+		}
+		if text := input[span.Start.Offset:span.End.Offset]; text != tt.text {
+			t.Fatalf("tests[%d] - text wrong. expected=%q, got=%q", i, tt.text, text)
+		}
+		if span.Start.Line != tt.line || span.Start.Column != tt.column || span.End.Line != tt.endLine || span.End.Column != tt.endColumn {
+			t.Fatalf("tests[%d] - span wrong. expected=%d:%d-%d:%d, got=%d:%d-%d:%d", i,
+				tt.line, tt.column, tt.endLine, tt.endColumn,
+				span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+		}
+	}
+}
+
+func TestWithImportStatement(t *testing.T) {
+	input := `import "lib/helpers.ac"
+Scenario "nested":
+    import "other.ac"
+`
+	stmnts, err := NewParser(lexer.NewLexer(input, "parser-test.ac")).ParseProgram()
+	statement, ok := stmnts[0].(*ast.Import)
+	if !ok {
+		t.Fatalf("statement wrong. expected=*ast.Import, got=%T", stmnts[0])
+	}
+	if statement.Path.Literal != "lib/helpers.ac" {
+		t.Fatalf("path wrong. expected=%q, got=%q", "lib/helpers.ac", statement.Path.Literal)
+	}
+
+	errors, ok := err.(merror.ErrorList)
+	if !ok || len(errors) != 1 {
+		t.Fatalf("errors wrong. expected=1 error, got=%v", err)
+	}
+	if errors[0].Line != 3 || errors[0].Message != "import is only allowed at the top level of a script" {
+		t.Fatalf("error wrong. got=%v", errors[0])
+	}
+}
@@ -0,0 +1,190 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/environment"
+	"github.com/itsert/ofin/script/interpreter"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/parser"
+	"github.com/itsert/ofin/script/resolver"
+)
+
+const PROMPT = ">> "
+
+// CONTINUATION is shown while a block is being typed. An empty line ends
+// the block.
+const CONTINUATION = ".. "
+
+const fileName = "<repl>"
+
+const help = `Enter ofin statements. A line ending in ':' starts a block, which an
+empty line ends. Meta-commands:
+  :state   show the step the interpreter is in
+  :vars    list the global variables
+  :reset   forget every variable and start over
+  :help    show this help
+  :quit    leave the REPL
+`
+
+// REPL interprets what is typed into it against a single Interpreter, so
+// variables and functions persist from one entry to the next.
+type REPL struct {
+	out         io.Writer
+	interpreter *interpreter.Interpreter
+	// state is carried from one entry to the next so the parser accepts what
+	// it would accept at the same point of a script.
+	state *environment.ProgramState
+	// block holds the lines of a block still being typed.
+	block []string
+}
+
+func New(out io.Writer) *REPL {
+	r := &REPL{out: out}
+	r.reset()
+	return r
+}
+
+// Start reads lines from in until it is exhausted or :quit is entered.
+func Start(in io.Reader, out io.Writer) {
+	r := New(out)
+	defer r.Close()
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, r.Prompt())
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		if !r.Line(scanner.Text()) {
+			return
+		}
+	}
+}
+
+// Close finishes the scenario still being typed, if any.
+func (r *REPL) Close() {
+	r.interpreter.Finish()
+}
+
+func (r *REPL) reset() {
+	if r.interpreter != nil {
+		r.interpreter.Finish()
+	}
+	r.interpreter = interpreter.NewInterpreter()
+	r.interpreter.File = fileName
+	r.interpreter.Output = r.out
+	r.state = environment.NewState()
+	r.block = nil
+}
+
+// Prompt is the prompt for the next line.
+func (r *REPL) Prompt() string {
+	if len(r.block) > 0 {
+		return CONTINUATION
+	}
+	return PROMPT
+}
+
+// Line handles one line of input and reports whether to keep reading.
+func (r *REPL) Line(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(r.block) > 0 {
+		if trimmed != "" {
+			r.block = append(r.block, line)
+			return true
+		}
+		source := strings.Join(r.block, "\n") + "\n"
+		r.block = nil
+		r.eval(source)
+		return true
+	}
+
+	switch {
+	case trimmed == "":
+	case strings.HasPrefix(trimmed, ":"):
+		return r.command(trimmed)
+	case strings.HasSuffix(trimmed, ":"):
+		r.block = []string{line}
+	default:
+		r.eval(line + "\n")
+	}
+	return true
+}
+
+func (r *REPL) command(command string) bool {
+	switch command {
+	case ":state":
+		fmt.Fprintln(r.out, r.interpreter.State())
+	case ":vars":
+		values := r.interpreter.Global.Values()
+		var names []string
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(r.out, "%s = %s\n", name, object.Inspect(values[name]))
+		}
+	case ":reset":
+		r.reset()
+	case ":help":
+		fmt.Fprint(r.out, help)
+	case ":quit", ":q":
+		return false
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, try :help\n", command)
+	}
+	return true
+}
+
+func (r *REPL) eval(source string) {
+	statements, err := parser.NewParserInState(lexer.NewLexer(source, fileName), r.state).ParseProgram()
+	if err != nil {
+		fmt.Fprintln(r.out, merror.Diagnostic(err, source))
+		return
+	}
+	if err := resolver.NewResolver(r.interpreter, fileName).Resolve(statements); err != nil {
+		fmt.Fprintln(r.out, merror.Diagnostic(err, source))
+		return
+	}
+
+	// A lone expression is evaluated so its value can be shown.
+	if len(statements) == 1 {
+		if statement, ok := statements[0].(*ast.StmtExpression); ok && !isAssignment(statement.Expr) {
+			value, err := r.interpreter.Evaluate(statement.Expr)
+			if err != nil {
+				fmt.Fprintln(r.out, merror.Diagnostic(err, source))
+			} else if value != nil {
+				fmt.Fprintln(r.out, object.Inspect(value))
+			}
+			return
+		}
+	}
+
+	failures := len(r.interpreter.Failures())
+	// The scenario typed in stays open so the steps of later entries are
+	// recorded in it.
+	if err := r.interpreter.Continue(context.Background(), statements); err != nil {
+		fmt.Fprintln(r.out, merror.Diagnostic(err, source))
+	}
+	for _, failure := range r.interpreter.Failures()[failures:] {
+		fmt.Fprintln(r.out, failure.Error())
+	}
+}
+
+func isAssignment(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.Assign, *ast.SetIndex, *ast.Set:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/itsert/ofin/script/result"
+)
+
+func TestREPL(t *testing.T) {
+	input := `Given a = 3
+a + 1
+fn square(x):
+    return x * x
+
+square(a)
+:vars
+Scenario "typed in":
+    Given b = 1
+    Then b == 2
+
+:state
+nope
+:reset
+:vars
+:quit
+a
+`
+	expected := []string{
+		PROMPT + PROMPT + "4",
+		CONTINUATION + CONTINUATION + PROMPT + "9",
+		"a = 3\n",
+		"square = <fn square>\n",
+		"[line 3] assertion failed: b == 2 (left: 1, right: 2)",
+		PROMPT + "THEN\n",
+		"<repl>:1:1 variable nope is undefined\n    nope\n    ^^^^",
+		PROMPT + PROMPT + "clock = <native fn clock>\nlen = <native fn len>\nprint_all = <native fn print_all>\nrange = <native fn range>\n",
+	}
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	got := out.String()
+	for i, want := range expected {
+		if !strings.Contains(got, want) {
+			t.Fatalf("tests[%d] - output wrong. expected to contain %q, got=%q", i, want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "range = <native fn range>\n"+PROMPT) {
+		t.Fatalf("input after :quit was read. got=%q", got)
+	}
+}
+
+func TestREPLScenarioAcrossEntries(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out)
+	for _, line := range []string{`Scenario "typed in":`, `    Given b = 1`, ``, `Given c = 2`, `Then c == 3`, `And c == 2`} {
+		r.Line(line)
+	}
+	r.Close()
+
+	scenarios := r.interpreter.Results().Scenarios
+	if len(scenarios) != 1 {
+		t.Fatalf("scenarios wrong. expected=%d, got=%d", 1, len(scenarios))
+	}
+	if steps := scenarios[0].Steps; len(steps) != 4 {
+		t.Fatalf("steps wrong. expected=%d, got=%d", 4, len(steps))
+	}
+	if status := scenarios[0].Status; status != result.FAILED {
+		t.Fatalf("status wrong. expected=%q, got=%q", result.FAILED, status)
+	}
+}
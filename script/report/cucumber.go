@@ -0,0 +1,110 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/itsert/ofin/script/result"
+)
+
+type cucumberFeature struct {
+	URI      string             `json:"uri"`
+	ID       string             `json:"id"`
+	Keyword  string             `json:"keyword"`
+	Name     string             `json:"name"`
+	Line     int                `json:"line"`
+	Elements []cucumberScenario `json:"elements"`
+}
+
+type cucumberScenario struct {
+	ID      string         `json:"id"`
+	Keyword string         `json:"keyword"`
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Line    int            `json:"line"`
+	Steps   []cucumberStep `json:"steps"`
+}
+
+type cucumberStep struct {
+	Keyword string         `json:"keyword"`
+	Name    string         `json:"name"`
+	Line    int            `json:"line"`
+	Result  cucumberResult `json:"result"`
+}
+
+type cucumberResult struct {
+	Status string `json:"status"`
+	// Duration is in nanoseconds, as Cucumber's own formatter writes it.
+	Duration     int64  `json:"duration"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+var nonIDCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Cucumber writes runs in the JSON format of Cucumber's json formatter, one
+// feature per script.
+func Cucumber(w io.Writer, runs []*result.Run) error {
+	features := []cucumberFeature{}
+	for _, run := range runs {
+		feature := cucumberFeature{
+			URI:      run.File,
+			ID:       cucumberID(run.File),
+			Keyword:  "Feature",
+			Name:     run.File,
+			Line:     1,
+			Elements: []cucumberScenario{},
+		}
+		for _, scenario := range run.Scenarios {
+			feature.Elements = append(feature.Elements, cucumberElement(feature.ID, scenario))
+		}
+		features = append(features, feature)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(features)
+}
+
+func cucumberElement(featureID string, scenario *result.Scenario) cucumberScenario {
+	element := cucumberScenario{
+		ID:      featureID + ";" + cucumberID(scenario.Name),
+		Keyword: "Scenario",
+		Type:    "scenario",
+		Name:    scenario.Name,
+		Line:    scenario.Line,
+		Steps:   []cucumberStep{},
+	}
+	for _, step := range scenario.Steps {
+		element.Steps = append(element.Steps, cucumberStep{
+			// Cucumber keeps the space that separates the keyword from the text.
+			Keyword: step.Keyword + " ",
+			Name:    step.Text,
+			Line:    step.Line,
+			Result:  cucumberStepResult(step),
+		})
+	}
+	return element
+}
+
+// cucumberStepResult maps a step status onto Cucumber's, which has no
+// errored status: a runtime error is reported as a failure with its message.
+func cucumberStepResult(step *result.Step) cucumberResult {
+	r := cucumberResult{
+		Status:   string(step.Status),
+		Duration: step.Duration.Nanoseconds(),
+	}
+	switch step.Status {
+	case result.FAILED:
+		r.ErrorMessage = step.Failure.Error()
+	case result.ERRORED:
+		r.Status = string(result.FAILED)
+		r.ErrorMessage = step.Error
+	}
+	return r
+}
+
+func cucumberID(name string) string {
+	return strings.Trim(nonIDCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/result"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// junitOutput is what a scenario printed.
+type junitOutput struct {
+	Body string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",cdata"`
+}
+
+// JUnit writes runs as a JUnit XML document with a testsuite per script and
+// a testcase per scenario.
+func JUnit(w io.Writer, runs []*result.Run) error {
+	suites := junitTestSuites{}
+	var total time.Duration
+	for _, run := range runs {
+		suite := junitTestSuite{
+			Name: run.File,
+			Time: seconds(run.Duration),
+		}
+		for _, scenario := range run.Scenarios {
+			suite.TestCases = append(suite.TestCases, junitCase(run.File, scenario))
+			suite.Tests += 1
+			switch scenario.Status {
+			case result.FAILED:
+				suite.Failures += 1
+			case result.ERRORED:
+				suite.Errors += 1
+			case result.SKIPPED:
+				suite.Skipped += 1
+			}
+		}
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		total += run.Duration
+	}
+	suites.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitCase(file string, scenario *result.Scenario) junitTestCase {
+	testCase := junitTestCase{
+		Name:      scenario.Name,
+		ClassName: file,
+		Time:      seconds(scenario.Duration),
+	}
+	switch scenario.Status {
+	case result.FAILED:
+		step := scenario.FailedStep()
+		failure := step.Failure
+		var body strings.Builder
+		fmt.Fprintf(&body, "%s %s\nat %s:%d", step.Keyword, failure.Expression, file, failure.Line)
+		if failure.HasOperands {
+			fmt.Fprintf(&body, "\nleft: %s\nright: %s", object.Inspect(failure.Left), object.Inspect(failure.Right))
+		}
+		testCase.Failure = &junitMessage{
+			Message: "assertion failed: " + failure.Expression,
+			Type:    "AssertionFailure",
+			Body:    body.String(),
+		}
+	case result.ERRORED:
+		testCase.Error = &junitMessage{
+			Message: scenario.ErrorMessage(),
+			Type:    "RuntimeError",
+			Body:    scenario.ErrorMessage(),
+		}
+	case result.SKIPPED:
+		testCase.Skipped = &junitMessage{}
+	}
+	if scenario.Output != "" && (testCase.Failure != nil || testCase.Error != nil) {
+		testCase.SystemOut = &junitOutput{Body: scenario.Output}
+	}
+	return testCase
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
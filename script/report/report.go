@@ -0,0 +1,18 @@
+package report
+
+import (
+	"io"
+
+	"github.com/itsert/ofin/script/result"
+)
+
+// Reporter writes the results of one or more runs in a file format.
+type Reporter func(w io.Writer, runs []*result.Run) error
+
+// Reporters maps the format names accepted by `--report format=path` to the
+// reporter that produces them.
+var Reporters = map[string]Reporter{
+	"cucumber": Cucumber,
+	"junit":    JUnit,
+	"tap":      TAP,
+}
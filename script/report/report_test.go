@@ -0,0 +1,181 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/itsert/ofin/script/result"
+)
+
+func testRun() *result.Run {
+	failure := &result.AssertionFailure{
+		Scenario:    "failing",
+		Line:        3,
+		Expression:  "a == 5",
+		HasOperands: true,
+		Operator:    "==",
+		Left:        4.0,
+		Right:       5.0,
+	}
+	return &result.Run{
+		Duration: 1500 * time.Millisecond,
+		Scenarios: []*result.Scenario{
+			{Name: "passing", Line: 1, Status: result.PASSED, Output: "not shown\n", Steps: []*result.Step{
+				{Keyword: "Given", Text: "a = 3", Line: 2, Status: result.PASSED},
+			}},
+			{Name: "failing", Line: 4, Status: result.FAILED, Output: "a is 4\nchecking\n", Steps: []*result.Step{
+				{Keyword: "Given", Text: "a = 4", Line: 5, Status: result.PASSED},
+				{Keyword: "Then", Text: "a == 5", Line: 6, Status: result.FAILED, Failure: failure},
+				{Keyword: "And", Text: "a > 1", Line: 7, Status: result.SKIPPED},
+			}},
+			{Name: "errors", Line: 8, Status: result.ERRORED, Steps: []*result.Step{
+				{Keyword: "When", Text: "b = nope", Line: 9, Status: result.ERRORED, Error: "[line 9] variable nope is undefined"},
+			}},
+		},
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var out bytes.Buffer
+	Summary(&out, testRun())
+
+	expected := `Scenario "failing" failed: [line 3] assertion failed: a == 5 (left: 4, right: 5)
+    a is 4
+    checking
+Scenario "errors" errored: [line 9] variable nope is undefined
+3 scenarios (1 passed, 1 failed, 1 errored)
+5 steps (2 passed, 1 failed, 1 errored, 1 skipped)
+0m1.500s
+`
+	if out.String() != expected {
+		t.Fatalf("summary wrong. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	run := testRun()
+	run.File = "test.ac"
+	var out bytes.Buffer
+	if err := JUnit(&out, []*result.Run{run}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" errors="1" skipped="0" time="1.500">
+  <testsuite name="test.ac" tests="3" failures="1" errors="1" skipped="0" time="1.500">
+    <testcase name="passing" classname="test.ac" time="0.000"></testcase>
+    <testcase name="failing" classname="test.ac" time="0.000">
+      <failure message="assertion failed: a == 5" type="AssertionFailure"><![CDATA[Then a == 5
+at test.ac:3
+left: 4
+right: 5]]></failure>
+      <system-out><![CDATA[a is 4
+checking
+]]></system-out>
+    </testcase>
+    <testcase name="errors" classname="test.ac" time="0.000">
+      <error message="[line 9] variable nope is undefined" type="RuntimeError"><![CDATA[[line 9] variable nope is undefined]]></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if out.String() != expected {
+		t.Fatalf("junit wrong. expected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestCucumber(t *testing.T) {
+	run := testRun()
+	run.File = "test.ac"
+	run.Scenarios = run.Scenarios[1:]
+	var out bytes.Buffer
+	if err := Cucumber(&out, []*result.Run{run}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var features []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &features); err != nil {
+		t.Fatalf("cucumber output is not JSON: %v", err)
+	}
+	if len(features) != 1 {
+		t.Fatalf("features wrong. expected=1, got=%d", len(features))
+	}
+	elements := features[0]["elements"].([]interface{})
+	if len(elements) != 2 {
+		t.Fatalf("elements wrong. expected=2, got=%d", len(elements))
+	}
+	failing := elements[0].(map[string]interface{})
+	if failing["id"] != "test-ac;failing" {
+		t.Fatalf("id wrong. expected=%q, got=%q", "test-ac;failing", failing["id"])
+	}
+
+	tests := []struct {
+		element int
+		step    int
+		keyword string
+		name    string
+		line    float64
+		status  string
+		message string
+	}{
+		{0, 0, "Given ", "a = 4", 5, "passed", ""},
+		{0, 1, "Then ", "a == 5", 6, "failed", "[line 3] assertion failed: a == 5 (left: 4, right: 5)"},
+		{0, 2, "And ", "a > 1", 7, "skipped", ""},
+		{1, 0, "When ", "b = nope", 9, "failed", "[line 9] variable nope is undefined"},
+	}
+	for i, tt := range tests {
+		steps := elements[tt.element].(map[string]interface{})["steps"].([]interface{})
+		step := steps[tt.step].(map[string]interface{})
+		r := step["result"].(map[string]interface{})
+		if step["keyword"] != tt.keyword || step["name"] != tt.name || step["line"] != tt.line {
+			t.Fatalf("tests[%d] - step wrong. expected=%q %q %v, got=%q %q %v", i, tt.keyword, tt.name, tt.line, step["keyword"], step["name"], step["line"])
+		}
+		if r["status"] != tt.status {
+			t.Fatalf("tests[%d] - status wrong. expected=%q, got=%q", i, tt.status, r["status"])
+		}
+		message, _ := r["error_message"].(string)
+		if message != tt.message {
+			t.Fatalf("tests[%d] - error_message wrong. expected=%q, got=%q", i, tt.message, message)
+		}
+	}
+}
+
+func TestTAP(t *testing.T) {
+	run := testRun()
+	run.File = "test.ac"
+	var out bytes.Buffer
+	if err := TAP(&out, []*result.Run{run}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := `TAP version 13
+1..3
+ok 1 - passing
+not ok 2 - failing
+  ---
+  message: "assertion failed"
+  severity: "fail"
+  expression: "a == 5"
+  operator: "=="
+  expected: "5"
+  actual: "4"
+  output: "a is 4\nchecking\n"
+  at:
+    file: "test.ac"
+    line: 3
+  ...
+not ok 3 - errors
+  ---
+  message: "[line 9] variable nope is undefined"
+  severity: "error"
+  at:
+    file: "test.ac"
+    line: 9
+  ...
+`
+	if out.String() != expected {
+		t.Fatalf("tap wrong. expected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
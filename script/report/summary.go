@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/itsert/ofin/script/result"
+)
+
+// Summary writes a Cucumber-style overview of run: the reason each failed or
+// errored scenario did not pass, with what it printed when that was
+// captured, followed by scenario and step counts and the total duration.
+func Summary(w io.Writer, run *result.Run) {
+	for _, scenario := range run.Scenarios {
+		switch scenario.Status {
+		case result.FAILED:
+			for _, failure := range scenario.Failures() {
+				fmt.Fprintf(w, "Scenario %q failed: %s\n", scenario.Name, failure)
+			}
+		case result.ERRORED:
+			fmt.Fprintf(w, "Scenario %q errored: %s\n", scenario.Name, scenario.ErrorMessage())
+		default:
+			continue
+		}
+		for _, line := range outputLines(scenario.Output) {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+	fmt.Fprintf(w, "%s%s\n", plural(len(run.Scenarios), "scenario"), counts(run.ScenarioCounts()))
+	fmt.Fprintf(w, "%s%s\n", plural(run.StepTotal(), "step"), counts(run.StepCounts()))
+	fmt.Fprintf(w, "%s\n", duration(run.Duration))
+}
+
+// outputLines splits captured output into lines, without the empty one
+// after the final newline.
+func outputLines(output string) []string {
+	if output == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func counts(counts map[result.Status]int) string {
+	var parts []string
+	for _, status := range result.Statuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// duration formats d the way Cucumber does, e.g. 0m0.012s.
+func duration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	seconds := d.Seconds() - float64(minutes*60)
+	return fmt.Sprintf("%dm%.3fs", minutes, seconds)
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/itsert/ofin/script/object"
+	"github.com/itsert/ofin/script/result"
+)
+
+// TAP writes runs in Test Anything Protocol version 13, one test point per
+// scenario. Failing and errored scenarios get a YAML diagnostic block.
+func TAP(w io.Writer, runs []*result.Run) error {
+	total := 0
+	for _, run := range runs {
+		total += len(run.Scenarios)
+	}
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n", total); err != nil {
+		return err
+	}
+
+	n := 0
+	for _, run := range runs {
+		for _, scenario := range run.Scenarios {
+			n += 1
+			if err := tapPoint(w, n, run.File, scenario); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tapPoint(w io.Writer, n int, file string, scenario *result.Scenario) error {
+	var err error
+	switch scenario.Status {
+	case result.PASSED:
+		_, err = fmt.Fprintf(w, "ok %d - %s\n", n, scenario.Name)
+	case result.SKIPPED:
+		_, err = fmt.Fprintf(w, "ok %d - %s # SKIP\n", n, scenario.Name)
+	case result.FAILED:
+		failure := scenario.FailedStep().Failure
+		diagnostic := [][2]string{
+			{"message", "assertion failed"},
+			{"severity", "fail"},
+			{"expression", failure.Expression},
+		}
+		if failure.HasOperands {
+			// TAP consumers look for expected and actual; an assertion is
+			// written as actual == expected.
+			diagnostic = append(diagnostic,
+				[2]string{"operator", failure.Operator},
+				[2]string{"expected", object.Inspect(failure.Right)},
+				[2]string{"actual", object.Inspect(failure.Left)},
+			)
+		}
+		err = tapNotOk(w, n, scenario.Name, withOutput(diagnostic, scenario), file, failure.Line)
+	case result.ERRORED:
+		line := scenario.Line
+		for _, step := range scenario.Steps {
+			if step.Status == result.ERRORED {
+				line = step.Line
+			}
+		}
+		diagnostic := [][2]string{
+			{"message", scenario.ErrorMessage()},
+			{"severity", "error"},
+		}
+		err = tapNotOk(w, n, scenario.Name, withOutput(diagnostic, scenario), file, line)
+	}
+	return err
+}
+
+// withOutput adds what the scenario printed to its diagnostic.
+func withOutput(diagnostic [][2]string, scenario *result.Scenario) [][2]string {
+	if scenario.Output == "" {
+		return diagnostic
+	}
+	return append(diagnostic, [2]string{"output", scenario.Output})
+}
+
+func tapNotOk(w io.Writer, n int, name string, diagnostic [][2]string, file string, line int) error {
+	if _, err := fmt.Fprintf(w, "not ok %d - %s\n  ---\n", n, name); err != nil {
+		return err
+	}
+	for _, entry := range diagnostic {
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", entry[0], strconv.Quote(entry[1])); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "  at:\n    file: %s\n    line: %d\n  ...\n", strconv.Quote(file), line)
+	return err
+}
@@ -0,0 +1,310 @@
+package resolver
+
+import (
+	"github.com/itsert/ofin/merror"
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/token"
+)
+
+// Locals is implemented by the interpreter, which keeps the scope depth of
+// every local variable reference the Resolver finds.
+type Locals interface {
+	Resolve(expression ast.Expression, depth int)
+}
+
+// Resolver is a static pass run between parsing and interpreting. It works out,
+// for every variable reference, how many scopes separate it from the scope that
+// declared it and hands that depth to the interpreter.
+type Resolver struct {
+	interpreter Locals
+	fileName    string
+	// scopes only tracks local scopes; anything not found in them is global.
+	// The value is false while a variable is declared but its initializer has
+	// not been resolved yet.
+	scopes []map[string]bool
+	errors merror.ErrorList
+	// imported holds the modules already resolved.
+	imported map[string]bool
+}
+
+func NewResolver(interpreter Locals, fileName string) *Resolver {
+	return &Resolver{
+		interpreter: interpreter,
+		fileName:    fileName,
+		imported:    map[string]bool{},
+	}
+}
+
+// Resolve resolves every statement. The error, if any, is a
+// merror.ErrorList with one error per top-level statement that failed.
+func (r *Resolver) Resolve(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		r.resolveDeclaration(stmt)
+	}
+	return r.errors.Err()
+}
+
+func (r *Resolver) resolveDeclaration(stmt ast.Statement) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			syntaxError, ok := rec.(*merror.SyntaxError)
+			if !ok {
+				panic(rec)
+			}
+			r.scopes = nil
+			r.errors = append(r.errors, syntaxError)
+		}
+	}()
+	r.resolveStatement(stmt)
+}
+
+func (r *Resolver) resolveStatements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		r.resolveStatement(stmt)
+	}
+}
+
+func (r *Resolver) resolveStatement(stmt ast.Statement) {
+	if stmt != nil {
+		stmt.Accept(r)
+	}
+}
+
+func (r *Resolver) resolveExpression(expr ast.Expression) {
+	if expr != nil {
+		expr.Accept(r)
+	}
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, map[string]bool{})
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *Resolver) declare(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	scope := r.scopes[len(r.scopes)-1]
+	if _, ok := scope[name.Lexeme]; ok {
+		merror.TokenError(r.fileName, name, "Already a variable with this name in this scope.")
+	}
+	scope[name.Lexeme] = false
+}
+
+func (r *Resolver) define(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.scopes[len(r.scopes)-1][name.Lexeme] = true
+}
+
+func (r *Resolver) resolveLocal(expr ast.Expression, name token.Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			r.interpreter.Resolve(expr, len(r.scopes)-1-i)
+			return
+		}
+	}
+}
+
+func (r *Resolver) resolveFunction(function *ast.Function) {
+	r.beginScope()
+	// A default is evaluated when the function is called, after the
+	// parameters before it have been bound.
+	for i, param := range function.Params {
+		r.declare(param)
+		r.resolveExpression(function.Defaults[i])
+		r.define(param)
+	}
+	r.resolveStatements(function.Body)
+	r.endScope()
+}
+
+func (r *Resolver) VisitBlockStatement(statement *ast.Block) interface{} {
+	r.beginScope()
+	r.resolveStatements(statement.Statements)
+	r.endScope()
+	return nil
+}
+
+func (r *Resolver) VisitVarStatement(statement *ast.Var) interface{} {
+	r.declare(statement.Name)
+	r.resolveExpression(statement.Initializer)
+	r.define(statement.Name)
+	return nil
+}
+
+func (r *Resolver) VisitFunctionStatement(statement *ast.Function) interface{} {
+	r.declare(statement.Name)
+	r.define(statement.Name)
+	r.resolveFunction(statement)
+	return nil
+}
+
+func (r *Resolver) VisitReturnStatement(statement *ast.Return) interface{} {
+	r.resolveExpression(statement.Value)
+	return nil
+}
+
+func (r *Resolver) VisitStmtExpressionStatement(statement *ast.StmtExpression) interface{} {
+	r.resolveExpression(statement.Expr)
+	return nil
+}
+
+func (r *Resolver) VisitIfStatement(statement *ast.If) interface{} {
+	r.resolveExpression(statement.Condition)
+	r.resolveStatement(statement.ThenBranch)
+	r.resolveStatement(statement.ElseBranch)
+	return nil
+}
+
+func (r *Resolver) VisitPrintStatement(statement *ast.Print) interface{} {
+	r.resolveExpression(statement.Expr)
+	return nil
+}
+
+func (r *Resolver) VisitWhenStatement(statement *ast.When) interface{} {
+	r.resolveExpression(statement.Expr)
+	r.resolveStatement(statement.Body)
+	return nil
+}
+
+func (r *Resolver) VisitThenStatement(statement *ast.Then) interface{} {
+	r.resolveExpression(statement.Expr)
+	r.resolveStatement(statement.Body)
+	return nil
+}
+
+func (r *Resolver) VisitAndStatement(statement *ast.And) interface{} {
+	r.resolveExpression(statement.Expr)
+	return nil
+}
+
+func (r *Resolver) VisitScenarioStatement(statement *ast.Scenario) interface{} {
+	return nil
+}
+
+func (r *Resolver) VisitWhileStatement(statement *ast.While) interface{} {
+	r.resolveExpression(statement.Condition)
+	r.resolveStatement(statement.Body)
+	return nil
+}
+
+func (r *Resolver) VisitForStatement(statement *ast.For) interface{} {
+	r.resolveExpression(statement.Iterable)
+	r.beginScope()
+	r.declare(statement.Name)
+	r.define(statement.Name)
+	r.resolveStatement(statement.Body)
+	r.endScope()
+	return nil
+}
+
+func (r *Resolver) VisitImportStatement(statement *ast.Import) interface{} {
+	module := statement.Module
+	if module == nil || r.imported[module.File] {
+		return nil
+	}
+	r.imported[module.File] = true
+	for _, stmt := range module.Statements {
+		r.resolveDeclaration(stmt)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitDoNotingStatement(statement *ast.DoNoting) interface{} {
+	return nil
+}
+
+func (r *Resolver) VisitAssignExpression(expression *ast.Assign) interface{} {
+	r.resolveExpression(expression.Expr)
+	r.resolveLocal(expression, expression.Name)
+	return nil
+}
+
+func (r *Resolver) VisitBinaryExpression(expression *ast.Binary) interface{} {
+	r.resolveExpression(expression.Left)
+	r.resolveExpression(expression.Right)
+	return nil
+}
+
+func (r *Resolver) VisitCallExpression(expression *ast.Call) interface{} {
+	r.resolveExpression(expression.Callee)
+	for _, argument := range expression.Arguments {
+		r.resolveExpression(argument)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitGroupingExpression(expression *ast.Grouping) interface{} {
+	r.resolveExpression(expression.Expr)
+	return nil
+}
+
+func (r *Resolver) VisitLiteralExpression(expression *ast.Literal) interface{} {
+	return nil
+}
+
+func (r *Resolver) VisitLogicalExpression(expression *ast.Logical) interface{} {
+	r.resolveExpression(expression.Left)
+	r.resolveExpression(expression.Right)
+	return nil
+}
+
+func (r *Resolver) VisitUnaryExpression(expression *ast.Unary) interface{} {
+	r.resolveExpression(expression.Right)
+	return nil
+}
+
+func (r *Resolver) VisitListExpression(expression *ast.List) interface{} {
+	for _, element := range expression.Elements {
+		r.resolveExpression(element)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitMapExpression(expression *ast.Map) interface{} {
+	for i := range expression.Keys {
+		r.resolveExpression(expression.Keys[i])
+		r.resolveExpression(expression.Values[i])
+	}
+	return nil
+}
+
+func (r *Resolver) VisitIndexExpression(expression *ast.Index) interface{} {
+	r.resolveExpression(expression.Object)
+	r.resolveExpression(expression.Key)
+	return nil
+}
+
+func (r *Resolver) VisitSetIndexExpression(expression *ast.SetIndex) interface{} {
+	r.resolveExpression(expression.Object)
+	r.resolveExpression(expression.Key)
+	r.resolveExpression(expression.Value)
+	return nil
+}
+
+func (r *Resolver) VisitGetExpression(expression *ast.Get) interface{} {
+	r.resolveExpression(expression.Object)
+	return nil
+}
+
+func (r *Resolver) VisitSetExpression(expression *ast.Set) interface{} {
+	r.resolveExpression(expression.Object)
+	r.resolveExpression(expression.Value)
+	return nil
+}
+
+func (r *Resolver) VisitVariableExpression(expression *ast.Variable) interface{} {
+	if len(r.scopes) > 0 {
+		if defined, ok := r.scopes[len(r.scopes)-1][expression.Name.Lexeme]; ok && !defined {
+			merror.TokenError(r.fileName, expression.Name, "Can't read local variable in its own initializer.")
+		}
+	}
+	r.resolveLocal(expression, expression.Name)
+	return nil
+}
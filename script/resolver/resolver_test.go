@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/itsert/ofin/script/ast"
+	"github.com/itsert/ofin/script/lexer"
+	"github.com/itsert/ofin/script/parser"
+)
+
+type locals map[ast.Expression]int
+
+func (l locals) Resolve(expression ast.Expression, depth int) {
+	l[expression] = depth
+}
+
+func resolve(t *testing.T, input string) (locals, error) {
+	t.Helper()
+	stmnts, err := parser.NewParser(lexer.NewLexer(input, "resolver-test.ac")).ParseProgram()
+	if err != nil {
+		t.Fatalf("unexpected parse error %v", err)
+	}
+	l := locals{}
+	return l, NewResolver(l, "resolver-test.ac").Resolve(stmnts)
+}
+
+func TestWithLocalDepth(t *testing.T) {
+	input := `Given g = 1
+fn outer(a):
+    fn inner():
+        return a + g
+    return inner
+`
+	l, err := resolve(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	depths := map[string]int{}
+	for expr, depth := range l {
+		if v, ok := expr.(*ast.Variable); ok {
+			depths[v.Name.Lexeme] = depth
+		}
+	}
+	if depth, ok := depths["a"]; !ok || depth != 1 {
+		t.Fatalf("depth of a wrong. expected=%d, got=%d", 1, depth)
+	}
+	if _, ok := depths["g"]; ok {
+		t.Fatalf("global g should not be resolved as a local")
+	}
+}
+
+func TestWithResolveErrors(t *testing.T) {
+	tests := []string{
+		"fn f(a, a):\n    return a\n",
+		"fn f():\n    Given a = 1\n    Given a = 2\n",
+		"fn f():\n    Given a = a\n",
+	}
+	for i, input := range tests {
+		if _, err := resolve(t, input); err == nil {
+			t.Fatalf("tests[%d] - expected a resolve error", i)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package result
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/itsert/ofin/script/object"
+)
+
+type Status string
+
+const (
+	PASSED  Status = "passed"
+	FAILED  Status = "failed"
+	ERRORED Status = "errored"
+	SKIPPED Status = "skipped"
+)
+
+// Statuses lists every Status in the order summaries report them.
+var Statuses = []Status{PASSED, FAILED, ERRORED, SKIPPED}
+
+// Run is the outcome of every scenario an interpreter has executed.
+type Run struct {
+	// File is the script the scenarios were read from.
+	File      string
+	Scenarios []*Scenario
+	Duration  time.Duration
+}
+
+type Scenario struct {
+	Name     string
+	Line     int
+	Status   Status
+	Steps    []*Step
+	Duration time.Duration
+	// Error is set when the scenario was aborted by a runtime error raised
+	// outside of any step.
+	Error string
+	// Output is what the scenario printed, when the interpreter captured it.
+	Output string
+}
+
+// Step is a single Given, When, Then or And line of a scenario.
+type Step struct {
+	Keyword  string
+	Text     string
+	Line     int
+	Status   Status
+	Duration time.Duration
+	// Failure is set when a Then assertion did not hold.
+	Failure *AssertionFailure
+	// Error is set when the step raised a runtime error.
+	Error string
+}
+
+// AssertionFailure is recorded for every Then, or And in the THEN state,
+// whose expression is not truthy.
+type AssertionFailure struct {
+	Scenario   string
+	Line       int
+	Expression string
+	// Operands are only captured when the assertion is a binary expression.
+	HasOperands bool
+	Operator    string
+	Left        interface{}
+	Right       interface{}
+}
+
+func (a *AssertionFailure) Error() string {
+	msg := fmt.Sprintf("[line %d] assertion failed: %s", a.Line, a.Expression)
+	if a.HasOperands {
+		msg += fmt.Sprintf(" (left: %s, right: %s)", object.Inspect(a.Left), object.Inspect(a.Right))
+	}
+	return msg
+}
+
+func NewRun() *Run {
+	return &Run{}
+}
+
+func NewScenario(name string, line int) *Scenario {
+	return &Scenario{
+		Name: name,
+		Line: line,
+	}
+}
+
+func NewStep(keyword string, text string, line int) *Step {
+	return &Step{
+		Keyword: keyword,
+		Text:    text,
+		Line:    line,
+	}
+}
+
+// Finish derives the scenario status from its steps unless it was already
+// marked as errored.
+func (s *Scenario) Finish(duration time.Duration) {
+	s.Duration = duration
+	if s.Status != "" {
+		return
+	}
+	s.Status = PASSED
+	for _, step := range s.Steps {
+		if step.Status == ERRORED {
+			s.Status = ERRORED
+			return
+		}
+		if step.Status == FAILED {
+			s.Status = FAILED
+		}
+	}
+}
+
+// FailedStep is the first step whose assertion did not hold.
+func (s *Scenario) FailedStep() *Step {
+	for _, step := range s.Steps {
+		if step.Failure != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+// Failures lists the assertion failures of the scenario in step order.
+func (s *Scenario) Failures() []*AssertionFailure {
+	var failures []*AssertionFailure
+	for _, step := range s.Steps {
+		if step.Failure != nil {
+			failures = append(failures, step.Failure)
+		}
+	}
+	return failures
+}
+
+// ErrorMessage is the runtime error that stopped the scenario, if any.
+func (s *Scenario) ErrorMessage() string {
+	if s.Error != "" {
+		return s.Error
+	}
+	for _, step := range s.Steps {
+		if step.Error != "" {
+			return step.Error
+		}
+	}
+	return ""
+}
+
+func (r *Run) Failed() bool {
+	for _, scenario := range r.Scenarios {
+		if scenario.Status == FAILED || scenario.Status == ERRORED {
+			return true
+		}
+	}
+	return false
+}
+
+// ScenarioCounts counts scenarios by status.
+func (r *Run) ScenarioCounts() map[Status]int {
+	counts := map[Status]int{}
+	for _, scenario := range r.Scenarios {
+		counts[scenario.Status] += 1
+	}
+	return counts
+}
+
+// StepCounts counts the steps of every scenario by status.
+func (r *Run) StepCounts() map[Status]int {
+	counts := map[Status]int{}
+	for _, scenario := range r.Scenarios {
+		for _, step := range scenario.Steps {
+			counts[step.Status] += 1
+		}
+	}
+	return counts
+}
+
+func (r *Run) StepTotal() int {
+	total := 0
+	for _, scenario := range r.Scenarios {
+		total += len(scenario.Steps)
+	}
+	return total
+}
@@ -7,6 +7,45 @@ type Token struct {
 	Lexeme  string
 	Literal interface{}
 	Line    int
+	// Column and EndColumn are the 1-based columns of the first character of
+	// the token and of the one just after it.
+	Column    int
+	EndColumn int
+	// Start and End are the byte offsets of the token in the script.
+	Start int
+	End   int
+	File  string
+}
+
+// Position is a location in a script.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span is the range of a script from Start up to, but not including, End.
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+func (t Token) Span() Span {
+	return Span{
+		File:  t.File,
+		Start: Position{Offset: t.Start, Line: t.Line, Column: t.Column},
+		End:   Position{Offset: t.End, Line: t.Line, Column: t.EndColumn},
+	}
+}
+
+// To is the span from the start of s to the end of other.
+func (s Span) To(other Span) Span {
+	return Span{
+		File:  s.File,
+		Start: s.Start,
+		End:   other.End,
+	}
 }
 
 func NewToken(
@@ -47,18 +86,21 @@ const (
 	INDENT        = "INDENT"
 	DEDENT        = "DEDENT"
 	// Delimiters
-	COMMA       = ","
-	SEMICOLON   = ";"
-	NEWLINE     = "NEWLINE"
-	LEFT_PAREN  = "("
-	RIGHT_PAREN = ")"
-	LEFT_BRACE  = "{"
-	RIGHT_BRACE = "}"
-	AND         = "AND"
-	LOGICAL_AND = "LOGICAL_AND"
-	LOGICAL_OR  = "LOGICAL_OR"
-	IN          = "IN"
-	NIL         = "NIL"
+	COMMA         = ","
+	SEMICOLON     = ";"
+	NEWLINE       = "NEWLINE"
+	COMMENT       = "COMMENT"
+	LEFT_PAREN    = "("
+	RIGHT_PAREN   = ")"
+	LEFT_BRACE    = "{"
+	RIGHT_BRACE   = "}"
+	LEFT_BRACKET  = "["
+	RIGHT_BRACKET = "]"
+	AND           = "AND"
+	LOGICAL_AND   = "LOGICAL_AND"
+	LOGICAL_OR    = "LOGICAL_OR"
+	IN            = "IN"
+	NIL           = "NIL"
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -69,6 +111,7 @@ const (
 	RETURN   = "RETURN"
 	WHILE    = "WHILE"
 	FOR      = "FOR"
+	IMPORT   = "IMPORT"
 	WHEN     = "WHEN"
 	SCENARIO = "SCENARIO"
 	THEN     = "THEN"
@@ -99,6 +142,7 @@ var keywords = map[string]TokenType{
 	"while":    WHILE,
 	"for":      FOR,
 	"in":       IN,
+	"import":   IMPORT,
 }
 
 func LookupIdentifier(ident string) TokenType {
@@ -30,6 +30,8 @@ func GenerateAST(outputDir string, fileName string, types []string) {
 	baseType := fileName
 	f.WriteString(fmt.Sprintf("type %s interface {\n", baseType))
 	f.WriteString(fmt.Sprintf("\t%s()\n", baseType))
+	f.WriteString("\tSpan() token.Span\n")
+	f.WriteString("\tSetSpan(span token.Span)\n")
 	f.WriteString(fmt.Sprintf("\tAccept(visitor %sVisitor) interface{}\n", baseType))
 	f.WriteString("}\n\n")
 
@@ -44,6 +46,7 @@ func GenerateAST(outputDir string, fileName string, types []string) {
 
 func defineType(f *os.File, baseName string, structName string, filedList string) {
 	f.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+	f.WriteString("\tSpanned\n")
 	fileds := strings.Split(filedList, ", ")
 	for _, field := range fileds {
 		f.WriteString(fmt.Sprintf("\t%s\n", field))